@@ -0,0 +1,8 @@
+// Package api holds the public contracts of filmoteka: the protobuf
+// definitions used for gRPC and the OpenAPI document of the HTTP API.
+package api
+
+import _ "embed"
+
+//go:embed openapi.json
+var OpenAPI []byte
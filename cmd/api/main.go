@@ -4,46 +4,193 @@ import (
 	"context"
 	"filmoteka/internal/config"
 	"filmoteka/internal/infrastructure"
+	"filmoteka/internal/logging"
 	"filmoteka/internal/repository"
+	"filmoteka/internal/repository/cache"
+	"filmoteka/internal/repository/memory"
 	"filmoteka/internal/service"
+	"filmoteka/internal/tracing"
 	"filmoteka/internal/transport"
+	"flag"
+	"fmt"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
+const usage = `usage: api [-config FILE] [config print]
+
+Serves the HTTP API on http.port and the gRPC API on grpc.port. config
+print writes the configuration in effect, with secrets redacted, and exits.
+Every config key can be overridden by an environment variable such as
+FILMOTEKA_POSTGRES_PASSWORD, or read from the file named by the same
+variable ending in _FILE.
+
+flags:
+`
+
 func main() {
 
-	if err := SetupViper(); err != nil {
-		log.Fatal(err.Error())
+	configPath := flag.String("config", "", "config file, configs/config.yaml by default")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	log.Info("viper OK")
+	args := flag.Args()
+	printConfig := len(args) == 2 && args[0] == "config" && args[1] == "print"
 
-	ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
+	if len(args) > 0 && !printConfig {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := config.SetupViper(*configPath); err != nil {
+		log.Fatal(err.Error())
+	}
 
-	pgConfig, err := config.GetDBConfig()
+	cfg, err := config.Load()
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	log.Info("got bd config")
+	if printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if err := logging.Configure(cfg.Log.Level, cfg.Log.Format); err != nil {
+		log.Fatal(err.Error())
+	}
 
-	db, err := infrastructure.SetUpPostgresDatabase(ctx, pgConfig)
+	log.Info("viper OK")
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	log.Info("connected to db")
+	var movieRepository service.MovieRepository
+	var actorRepository service.ActorRepository
+	var auditRepository service.AuditRepository
+	var idempotencyRepository service.IdempotencyRepository
+	var webhookRepository service.WebhookRepository
+	var apiKeyRepository service.APIKeyRepository
+	var transactor service.Transactor
+
+	switch cfg.Storage {
+	case config.StorageMemory:
+		store := memory.NewStore()
+		movieRepository = memory.NewMovieRepository(store)
+		actorRepository = memory.NewActorRepository(store)
+		auditRepository = memory.NewAuditRepository(store)
+		idempotencyRepository = memory.NewIdempotencyRepository()
+		webhookRepository = memory.NewWebhookRepository(store)
+		apiKeyRepository = memory.NewAPIKeyRepository()
+		transactor = memory.NewTransactor(store)
+
+		log.Info("using in-memory storage")
+	default:
+		pgConfig := cfg.Postgres
+
+		db, err := infrastructure.SetUpPostgresDatabase(context.Background(), pgConfig)
+
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		log.Info("connected to db")
+
+		var replicas *repository.Replicas
+
+		if len(pgConfig.Replicas) > 0 {
+			replicaDBs, err := infrastructure.SetUpPostgresReplicas(pgConfig)
+
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			replicas = repository.NewReplicas(db, replicaDBs, pgConfig.ReplicaStickiness)
+			go replicas.Run(context.Background(), pgConfig.ReplicaCheckInterval, pgConfig.ConnectTimeout)
+
+			log.WithField("replicas", len(replicaDBs)).Info("routing reads to replicas")
+		}
+
+		movieRepository = repository.NewMovieRepository(db, replicas)
+		actorRepository = repository.NewActorRepository(db, replicas)
+		auditRepository = repository.NewAuditRepository(db)
+		idempotencyRepository = repository.NewIdempotencyRepository(db)
+		webhookRepository = repository.NewWebhookRepository(db)
+		apiKeyRepository = repository.NewAPIKeyRepository(db)
+		transactor = repository.NewTransactor(db)
+
+		if replicas != nil {
+			transactor = service.OnCommit(transactor, replicas.Wrote)
+		}
+	}
+
+	cacheConfig := cfg.Cache
 
-	//actorRepository := repository.NewActorRepository(db)
-	movieRepository := repository.NewMovieRepository(db)
-	movieService := service.NewMovieService(movieRepository)
+	var cacheBackend cache.Backend
+
+	switch cacheConfig.Backend {
+	case config.CacheMemory:
+		cacheBackend = cache.NewLRU(cacheConfig.Size, cacheConfig.TTL)
+	case config.CacheRedis:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		client, err := infrastructure.SetUpRedis(ctx, &cacheConfig.Redis)
+
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		cacheBackend = cache.NewRedis(client, cacheConfig.Redis.Prefix, cacheConfig.TTL)
+	}
+
+	if cacheBackend != nil {
+		cachedMovies := cache.NewCachedMovieRepository(movieRepository, cacheBackend)
+		movieRepository = cachedMovies
+		transactor = service.OnCommit(transactor, func(ctx context.Context) {
+			if err := cachedMovies.Invalidate(ctx); err != nil {
+				logging.From(ctx).WithError(err).Error("cache invalidation failed")
+			}
+		})
+
+		log.Info("caching movie lists in ", cacheConfig.Backend)
+	}
+
+	eventService := service.NewEventService(auditRepository)
+	transactor = service.OnCommit(transactor, func(context.Context) { eventService.Notify() })
+
+	actorService := service.NewActorService(actorRepository, transactor)
+	movieService := service.NewMovieService(movieRepository, transactor)
 	movieHandler := transport.NewMovieHandler(movieService)
+	actorHandler := transport.NewActorHandler(actorService)
+	auditHandler := transport.NewAuditHandler(service.NewAuditService(auditRepository))
+	batchHandler := transport.NewBatchHandler(service.NewBatchService(movieService, actorService, transactor))
+	eventHandler := transport.NewEventHandler(eventService)
+	webhookHandler := transport.NewWebhookHandler(service.NewWebhookService(webhookRepository))
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository)
+	apiKeyHandler := transport.NewAPIKeyHandler(apiKeyService)
+
+	purgeConfig := cfg.Purge
+	idempotencyService := service.NewIdempotencyService(idempotencyRepository, cfg.Idempotency.TTL)
+	webhookConfig := cfg.Webhooks
+
+	webhookClient := service.NewWebhookClient(webhookConfig.Timeout)
+	go service.NewWebhookDispatcher(webhookRepository, webhookClient, webhookConfig.Interval, webhookConfig.Backoff, webhookConfig.MaxBackoff, webhookConfig.MaxAttempts).Run(context.Background())
+
+	go service.NewPurgeJob(movieService, actorService, purgeConfig.Retention, purgeConfig.Interval).Run(context.Background())
 	// actorRepository.DeleteActor(ctx, 4)
 
 	// actor1 := core.Actor{Name: "benedict cumberbatch", Sex: 109, Bd: "1976-07-19"}
@@ -61,20 +208,32 @@ func main() {
 	// 	log.Info(err.Error())
 	// }
 
-	http.HandleFunc("/movie", movieHandler.CreateMovie)
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
 
-}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
 
-func SetupViper() error {
+	grpcServer := transport.NewGRPCServer(cfg.GRPC, movieService, actorService)
+	go func() {
+		log.Fatal(grpcServer.Serve(grpcListener))
+	}()
 
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("configs")
-	viper.SetConfigName("config")
+	log.Info("grpc server listening on ", grpcListener.Addr())
+	if cfg.GRPC.Token == "" {
+		log.Warn("grpc token is not set, every grpc call is refused")
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		return err
+	var rateLimiter *transport.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter = transport.NewRateLimiter(cfg.RateLimit)
 	}
 
-	return nil
+	authenticator := transport.NewAuthenticator(apiKeyService, cfg.Auth)
+
+	router := transport.NewRouter(movieHandler, actorHandler, auditHandler, batchHandler, eventHandler, webhookHandler, apiKeyHandler, authenticator, rateLimiter, idempotencyService)
+	err = http.ListenAndServe(":"+cfg.HTTP.Port, router)
+	shutdownTracing(context.Background())
+	log.Fatal(err)
+
 }
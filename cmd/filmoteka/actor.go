@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"flag"
+	"fmt"
+	"unicode/utf8"
+)
+
+func runActor(ctx context.Context, b backend, out *printer, command string, args []string) error {
+
+	flags := flag.NewFlagSet("actor "+command, flag.ContinueOnError)
+
+	switch command {
+	case "create":
+		name := flags.String("name", "", "actor name")
+		sex := flags.String("sex", "", "m or f")
+		bd := flags.String("bd", "", "birthday, YYYY-MM-DD")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		s, _ := utf8.DecodeRuneInString(*sex)
+		actor := &core.Actor{Name: *name, Sex: s, Bd: *bd}
+		if err := b.CreateActor(ctx, actor); err != nil {
+			return err
+		}
+		return out.Actors(actor)
+
+	case "update":
+		column := flags.String("column", "", "column to update: name, sex or bd")
+		value := flags.String("value", "", "new value")
+		version := flags.Int("version", 0, "fail unless the actor is at this version")
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if err := b.UpdateActor(ctx, id, *version, *column, *value); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("actor %d updated", id))
+
+	case "delete":
+		version := flags.Int("version", 0, "fail unless the actor is at this version")
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if err := b.DeleteActor(ctx, id, *version); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("actor %d deleted", id))
+
+	case "restore":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+
+		if err := b.RestoreActor(ctx, id); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("actor %d restored", id))
+
+	case "list":
+		actors, err := b.ListActors(ctx)
+		if err != nil {
+			return err
+		}
+		return out.Actors(actors...)
+	}
+
+	return fmt.Errorf("unknown actor command %q", command)
+}
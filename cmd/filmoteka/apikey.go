@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func runAPIKey(ctx context.Context, b backend, out *printer, command string, args []string) error {
+
+	flags := flag.NewFlagSet("apikey "+command, flag.ContinueOnError)
+
+	switch command {
+	case "issue":
+		name := flags.String("name", "", "what the key is for, recorded as the user of its changes")
+		scopes := flags.String("scopes", "", "comma separated scopes: movies:read, movies:write, actors:read, actors:write, admin")
+		expires := flags.Duration("expires", 0, "expire the key after this long, never if 0")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		key := &core.APIKey{Name: *name}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				key.Scopes = append(key.Scopes, scope)
+			}
+		}
+		if *expires > 0 {
+			at := time.Now().Add(*expires)
+			key.ExpiresAt = &at
+		}
+
+		if err := b.IssueAPIKey(ctx, key); err != nil {
+			return err
+		}
+		return out.APIKeys(key)
+
+	case "list":
+		keys, err := b.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		return out.APIKeys(keys...)
+
+	case "revoke":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+
+		if err := b.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("API key %d revoked", id))
+	}
+
+	return fmt.Errorf("unknown apikey command %q", command)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"filmoteka/internal/infrastructure"
+	"filmoteka/internal/repository"
+	"filmoteka/internal/repository/cache"
+	"filmoteka/internal/service"
+	"filmoteka/pkg/client"
+	"fmt"
+	"os"
+)
+
+// backend is what the commands run against: either the service layer
+// connected straight to the database or the HTTP API.
+type backend interface {
+	CreateMovie(ctx context.Context, movie *core.Movie) error
+	UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	DeleteMovie(ctx context.Context, id int, version int) error
+	RestoreMovie(ctx context.Context, id int) error
+	ListMovies(ctx context.Context, sorting string) ([]*core.Movie, error)
+	SearchMovies(ctx context.Context, search string) ([]*core.Movie, error)
+	AddActors(ctx context.Context, id int, cast []core.CastMember) error
+	DeleteActors(ctx context.Context, id int, cast []core.CastMember) error
+
+	CreateActor(ctx context.Context, actor *core.Actor) error
+	UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	DeleteActor(ctx context.Context, id int, version int) error
+	RestoreActor(ctx context.Context, id int) error
+	ListActors(ctx context.Context) ([]*core.Actor, error)
+
+	IssueAPIKey(ctx context.Context, key *core.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]*core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+}
+
+type serviceBackend struct {
+	*service.MovieService
+	*service.ActorService
+	*service.APIKeyService
+}
+
+func newServiceBackend(ctx context.Context, configPath string) (*serviceBackend, error) {
+
+	if err := config.SetupViper(configPath); err != nil {
+		return nil, err
+	}
+
+	pgConfig, err := config.GetDBConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := infrastructure.SetUpPostgresDatabase(ctx, pgConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	transactor, err := invalidatingTransactor(ctx, repository.NewTransactor(db))
+	if err != nil {
+		return nil, err
+	}
+
+	return &serviceBackend{
+		MovieService: service.NewMovieService(repository.NewMovieRepository(db, nil), transactor),
+		ActorService: service.NewActorService(repository.NewActorRepository(db, nil), transactor),
+		// Keys are issued here without one, which is how the first admin
+		// key of a deployment requiring keys is made.
+		APIKeyService: service.NewAPIKeyService(repository.NewAPIKeyRepository(db)),
+	}, nil
+}
+
+// invalidatingTransactor makes changes invalidate the movie lists the API
+// servers cache in Redis, as their own changes do. A cache in the memory of
+// a server can not be reached, its lists are stale for up to the cache ttl.
+func invalidatingTransactor(ctx context.Context, transactor service.Transactor) (service.Transactor, error) {
+
+	cacheConfig, err := config.GetCacheConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheConfig.Backend != config.CacheRedis {
+		return transactor, nil
+	}
+
+	client, err := infrastructure.SetUpRedis(ctx, &cacheConfig.Redis)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := cache.NewRedis(client, cacheConfig.Redis.Prefix, cacheConfig.TTL)
+	return service.OnCommit(transactor, func(ctx context.Context) {
+		if err := backend.Invalidate(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "filmoteka: cache invalidation failed:", err)
+		}
+	}), nil
+}
+
+func (b *serviceBackend) ListMovies(ctx context.Context, sorting string) ([]*core.Movie, error) {
+	return b.MovieService.GetAll(ctx, sorting)
+}
+
+func (b *serviceBackend) SearchMovies(ctx context.Context, search string) ([]*core.Movie, error) {
+	return b.MovieService.SearchMovie(ctx, search)
+}
+
+func (b *serviceBackend) ListActors(ctx context.Context) ([]*core.Actor, error) {
+	return b.ActorService.GetAllActors(ctx)
+}
+
+type apiBackend struct {
+	client *client.Client
+}
+
+func newAPIBackend(baseURL, token, apiKey string) *apiBackend {
+	return &apiBackend{client: client.New(baseURL, client.WithToken(token), client.WithAPIKey(apiKey))}
+}
+
+func (b *apiBackend) CreateMovie(ctx context.Context, movie *core.Movie) error {
+	created, err := b.client.CreateMovie(ctx, movieToClient(movie))
+	if err != nil {
+		return err
+	}
+	*movie = *movieFromClient(created)
+	return nil
+}
+
+func (b *apiBackend) UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	return b.client.UpdateMovie(ctx, id, version, columnName, newValue)
+}
+
+func (b *apiBackend) DeleteMovie(ctx context.Context, id int, version int) error {
+	return b.client.DeleteMovie(ctx, id, version)
+}
+
+func (b *apiBackend) RestoreMovie(ctx context.Context, id int) error {
+	return b.client.RestoreMovie(ctx, id)
+}
+
+func (b *apiBackend) ListMovies(ctx context.Context, sorting string) ([]*core.Movie, error) {
+	movies, err := b.client.ListMovies(ctx, sorting)
+	return moviesFromClient(movies), err
+}
+
+func (b *apiBackend) SearchMovies(ctx context.Context, search string) ([]*core.Movie, error) {
+	movies, err := b.client.SearchMovies(ctx, search)
+	return moviesFromClient(movies), err
+}
+
+func (b *apiBackend) AddActors(ctx context.Context, id int, cast []core.CastMember) error {
+	return b.client.AddActors(ctx, id, castToClient(cast))
+}
+
+func (b *apiBackend) DeleteActors(ctx context.Context, id int, cast []core.CastMember) error {
+	return b.client.DeleteActors(ctx, id, castToClient(cast))
+}
+
+func (b *apiBackend) CreateActor(ctx context.Context, actor *core.Actor) error {
+	created, err := b.client.CreateActor(ctx, actorToClient(actor))
+	if err != nil {
+		return err
+	}
+	*actor = *actorFromClient(created)
+	return nil
+}
+
+func (b *apiBackend) UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	return b.client.UpdateActor(ctx, id, version, columnName, newValue)
+}
+
+func (b *apiBackend) DeleteActor(ctx context.Context, id int, version int) error {
+	return b.client.DeleteActor(ctx, id, version)
+}
+
+func (b *apiBackend) RestoreActor(ctx context.Context, id int) error {
+	return b.client.RestoreActor(ctx, id)
+}
+
+func (b *apiBackend) ListActors(ctx context.Context) ([]*core.Actor, error) {
+	actors, err := b.client.ListActors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*core.Actor, 0, len(actors))
+	for _, actor := range actors {
+		result = append(result, actorFromClient(actor))
+	}
+	return result, nil
+}
+
+func (b *apiBackend) IssueAPIKey(ctx context.Context, key *core.APIKey) error {
+	issued, err := b.client.IssueAPIKey(ctx, &client.APIKey{Name: key.Name, Scopes: key.Scopes, ExpiresAt: key.ExpiresAt})
+	if err != nil {
+		return err
+	}
+	*key = *apiKeyFromClient(issued)
+	return nil
+}
+
+func (b *apiBackend) ListAPIKeys(ctx context.Context) ([]*core.APIKey, error) {
+	keys, err := b.client.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*core.APIKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, apiKeyFromClient(key))
+	}
+	return result, nil
+}
+
+func (b *apiBackend) RevokeAPIKey(ctx context.Context, id int) error {
+	return b.client.RevokeAPIKey(ctx, id)
+}
+
+func movieToClient(movie *core.Movie) *client.Movie {
+	return &client.Movie{Id: movie.Id, Title: movie.Title, Descr: movie.Descr, Release: movie.Release, Rating: movie.Rating, Actors: movie.Actors}
+}
+
+func movieFromClient(movie *client.Movie) *core.Movie {
+	return &core.Movie{Id: movie.Id, Title: movie.Title, Descr: movie.Descr, Release: movie.Release, Rating: movie.Rating, Actors: movie.Actors}
+}
+
+func moviesFromClient(movies []*client.Movie) []*core.Movie {
+	result := make([]*core.Movie, 0, len(movies))
+	for _, movie := range movies {
+		result = append(result, movieFromClient(movie))
+	}
+	return result
+}
+
+func actorToClient(actor *core.Actor) *client.Actor {
+	return &client.Actor{Id: actor.Id, Name: actor.Name, Sex: actor.Sex, Bd: actor.Bd, Movies: actor.Movies}
+}
+
+func actorFromClient(actor *client.Actor) *core.Actor {
+	return &core.Actor{Id: actor.Id, Name: actor.Name, Sex: actor.Sex, Bd: actor.Bd, Movies: actor.Movies}
+}
+
+func castToClient(cast []core.CastMember) []client.CastMember {
+	members := make([]client.CastMember, 0, len(cast))
+	for _, member := range cast {
+		members = append(members, client.CastMember(member))
+	}
+	return members
+}
+
+func apiKeyFromClient(key *client.APIKey) *core.APIKey {
+	return &core.APIKey{Id: key.Id, Name: key.Name, Key: key.Key, Prefix: key.Prefix, Scopes: key.Scopes, ExpiresAt: key.ExpiresAt, CreatedAt: key.CreatedAt, LastUsedAt: key.LastUsedAt}
+}
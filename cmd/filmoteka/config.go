@@ -0,0 +1,27 @@
+package main
+
+import (
+	"filmoteka/internal/config"
+	"fmt"
+	"io"
+)
+
+// runConfig works on the server configuration read from path, no backend
+// is needed.
+func runConfig(path string, w io.Writer, command string) error {
+
+	switch command {
+	case "print":
+		if err := config.SetupViper(path); err != nil {
+			return err
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		return config.Print(w, cfg)
+	}
+
+	return fmt.Errorf("unknown config command %q", command)
+}
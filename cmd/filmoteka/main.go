@@ -0,0 +1,156 @@
+// Command filmoteka manages the movie catalogue from the command line,
+// either directly against the configured database or through the HTTP API.
+package main
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const usage = `usage: filmoteka [-config FILE] [-api URL] [-token TOKEN] [-api-key KEY] [-user NAME] [-output table|json] <command> [flags] [args]
+
+commands:
+  movie create -title T -descr D -release YYYY-MM-DD -rating N [-actors 1,2]
+  movie update <id> -column C -value V [-version N]
+  movie delete <id> [-version N]
+  movie restore <id>
+  movie list [-sort rating|title|release]
+  movie search <query>
+  movie add-actors <id> <actor id or name>[=<character>]...
+  movie remove-actors <id> <actor id or name>...
+  actor create -name N -sex m|f -bd YYYY-MM-DD
+  actor update <id> -column C -value V [-version N]
+  actor delete <id> [-version N]
+  actor restore <id>
+  actor list
+  apikey issue -name N -scopes movies:read,actors:read [-expires 720h]
+  apikey list
+  apikey revoke <id>
+  config print
+
+Without -api the database is used as configured in FILE, configs/config.yaml
+by default, with keys overridden by environment variables such as
+FILMOTEKA_POSTGRES_PASSWORD or read from the file FILMOTEKA_POSTGRES_PASSWORD_FILE
+names. config print writes that configuration with secrets redacted.
+-user names the user recorded in the audit log; with -api, changes are
+recorded under the name of the API key instead.
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "filmoteka:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+
+	global := flag.NewFlagSet("filmoteka", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configPath := global.String("config", "", "config file of the database, configs/config.yaml by default")
+	apiURL := global.String("api", "", "call the HTTP API at this URL instead of the database")
+	token := global.String("token", "", "token sent to the HTTP API")
+	apiKey := global.String("api-key", os.Getenv("FILMOTEKA_API_KEY"), "API key sent to the HTTP API")
+	user := global.String("user", os.Getenv("USER"), "user recorded in the audit log when working on the database; through the API changes are recorded under the API key")
+	output := global.String("output", "table", "output format: table or json")
+
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+
+	out, err := newPrinter(*output, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	rest := global.Args()
+	if len(rest) < 2 {
+		global.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	if rest[0] == "config" {
+		return runConfig(*configPath, os.Stdout, rest[1])
+	}
+
+	ctx := core.WithUser(context.Background(), *user)
+
+	var b backend
+	if *apiURL != "" {
+		if flagSet(global, "user") {
+			return fmt.Errorf("-user only applies to the database, the API records changes under the API key")
+		}
+		b = newAPIBackend(*apiURL, *token, *apiKey)
+	} else if b, err = newServiceBackend(ctx, *configPath); err != nil {
+		return err
+	}
+
+	switch rest[0] {
+	case "movie":
+		return runMovie(ctx, b, out, rest[1], rest[2:])
+	case "actor":
+		return runActor(ctx, b, out, rest[1], rest[2:])
+	case "apikey":
+		return runAPIKey(ctx, b, out, rest[1], rest[2:])
+	}
+
+	global.Usage()
+	return fmt.Errorf("unknown command %q", rest[0])
+}
+
+// parseID parses the first positional argument as an id.
+// flagSet tells whether the flag name was given on the command line.
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func parseID(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, fmt.Errorf("missing id")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", args[0])
+	}
+	return id, nil
+}
+
+// parseMovieValue sends the value of the integer rating column as a
+// number, the other columns are text and get it as a string, "1984" as
+// well.
+func parseMovieValue(column string, value string) (interface{}, error) {
+	if column != "rating" {
+		return value, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rating %q", value)
+	}
+	return n, nil
+}
+
+func parseIDs(list string) ([]int, error) {
+	ids := []int{}
+	for _, field := range strings.Split(list, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseMovieValue(t *testing.T) {
+
+	tests := []struct {
+		column  string
+		value   string
+		want    interface{}
+		wantErr bool
+	}{
+		{column: "title", value: "1984", want: "1984"},
+		{column: "descr", value: "42", want: "42"},
+		{column: "release", value: "2010-07-25", want: "2010-07-25"},
+		{column: "rating", value: "7", want: 7},
+		{column: "rating", value: "seven", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := parseMovieValue(test.column, test.value)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseMovieValue(%q, %q) error = %v, want error %v", test.column, test.value, err, test.wantErr)
+			continue
+		}
+		if got != test.want && !test.wantErr {
+			t.Errorf("parseMovieValue(%q, %q) = %#v, want %#v", test.column, test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func runMovie(ctx context.Context, b backend, out *printer, command string, args []string) error {
+
+	flags := flag.NewFlagSet("movie "+command, flag.ContinueOnError)
+
+	switch command {
+	case "create":
+		title := flags.String("title", "", "movie title")
+		descr := flags.String("descr", "", "movie description")
+		release := flags.String("release", "", "release date, YYYY-MM-DD")
+		rating := flags.Int("rating", 0, "rating from 0 to 10")
+		actors := flags.String("actors", "", "comma separated actor ids")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		ids, err := parseIDs(*actors)
+		if err != nil {
+			return err
+		}
+
+		movie := &core.Movie{Title: *title, Descr: *descr, Release: *release, Rating: *rating, Actors: ids}
+		if err := b.CreateMovie(ctx, movie); err != nil {
+			return err
+		}
+		return out.Movies(movie)
+
+	case "update":
+		column := flags.String("column", "", "column to update: title, descr, release or rating")
+		value := flags.String("value", "", "new value")
+		version := flags.Int("version", 0, "fail unless the movie is at this version")
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		newValue, err := parseMovieValue(*column, *value)
+		if err != nil {
+			return err
+		}
+
+		if err := b.UpdateMovie(ctx, id, *version, *column, newValue); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("movie %d updated", id))
+
+	case "delete":
+		version := flags.Int("version", 0, "fail unless the movie is at this version")
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		if err := b.DeleteMovie(ctx, id, *version); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("movie %d deleted", id))
+
+	case "restore":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+
+		if err := b.RestoreMovie(ctx, id); err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("movie %d restored", id))
+
+	case "list":
+		sorting := flags.String("sort", "rating", "sort by rating, title or release")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+
+		movies, err := b.ListMovies(ctx, *sorting)
+		if err != nil {
+			return err
+		}
+		return out.Movies(movies...)
+
+	case "search":
+		movies, err := b.SearchMovies(ctx, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		return out.Movies(movies...)
+
+	case "add-actors", "remove-actors":
+		id, err := parseID(args)
+		if err != nil {
+			return err
+		}
+		if len(args) < 2 {
+			return fmt.Errorf("missing actors")
+		}
+
+		cast := parseCast(args[1:])
+		if command == "add-actors" {
+			err = b.AddActors(ctx, id, cast)
+		} else {
+			err = b.DeleteActors(ctx, id, cast)
+		}
+		if err != nil {
+			return err
+		}
+		return out.Done(fmt.Sprintf("movie %d actors updated", id))
+	}
+
+	return fmt.Errorf("unknown movie command %q", command)
+}
+
+// parseCast reads actors given as <id or name>[=<character>].
+func parseCast(args []string) []core.CastMember {
+	cast := make([]core.CastMember, 0, len(args))
+	for _, arg := range args {
+		actor, character, _ := strings.Cut(arg, "=")
+		member := core.CastMember{Character: character}
+		if id, err := strconv.Atoi(actor); err == nil {
+			member.ActorId = id
+		} else {
+			member.ActorName = actor
+		}
+		cast = append(cast, member)
+	}
+	return cast
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"filmoteka/internal/core"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type printer struct {
+	json bool
+	w    io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{json: true, w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+func (p *printer) encode(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) Movies(movies ...*core.Movie) error {
+	if p.json {
+		if movies == nil {
+			movies = []*core.Movie{}
+		}
+		return p.encode(movies)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tRELEASE\tRATING\tACTORS")
+	for _, movie := range movies {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n", movie.Id, movie.Title, movie.Release, movie.Rating, joinIDs(movie.Actors))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) Actors(actors ...*core.Actor) error {
+	if p.json {
+		if actors == nil {
+			actors = []*core.Actor{}
+		}
+		return p.encode(actors)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSEX\tBIRTHDAY\tMOVIES")
+	for _, actor := range actors {
+		fmt.Fprintf(tw, "%d\t%s\t%c\t%s\t%s\n", actor.Id, actor.Name, actor.Sex, actor.Bd, joinIDs(actor.Movies))
+	}
+	return tw.Flush()
+}
+
+// APIKeys prints keys, and the key itself of a key that was just issued.
+func (p *printer) APIKeys(keys ...*core.APIKey) error {
+	if p.json {
+		if keys == nil {
+			keys = []*core.APIKey{}
+		}
+		return p.encode(keys)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES\tLAST USED")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", key.Id, key.Name, key.Prefix, strings.Join(key.Scopes, ","), formatTime(key.ExpiresAt), formatTime(key.LastUsedAt))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.Key != "" {
+			fmt.Fprintf(p.w, "\nkey: %s\nit is not shown again, store it now\n", key.Key)
+		}
+	}
+	return nil
+}
+
+// Done reports a successful command that has nothing to print.
+func (p *printer) Done(msg string) error {
+	if p.json {
+		return p.encode(map[string]string{"status": msg})
+	}
+	_, err := fmt.Fprintln(p.w, msg)
+	return err
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprint(id))
+	}
+	return strings.Join(parts, ",")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
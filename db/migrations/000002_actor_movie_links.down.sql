@@ -0,0 +1,22 @@
+ALTER TABLE ActorMovie DROP CONSTRAINT IF EXISTS actormovie_pkey;
+
+CREATE OR REPLACE FUNCTION add_actors_to_movie(actor_ids integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO ActorMovie (actor_id, movie_id)
+    SELECT actor_id, movie_id
+    FROM unnest(actor_ids) AS actor_id
+    WHERE EXISTS (
+        SELECT 1
+        FROM Actors a
+        WHERE a.id = actor_id
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION delete_actors_from_movie(actor_ids integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    DELETE FROM ActorMovie
+    WHERE actor_id = ANY(actor_ids)
+    AND movie_id = movie_id;
+END;
+$$ LANGUAGE plpgsql;
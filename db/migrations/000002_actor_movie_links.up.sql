@@ -0,0 +1,22 @@
+DELETE FROM ActorMovie WHERE actor_id IS NULL OR movie_id IS NULL;
+
+DELETE FROM ActorMovie a USING ActorMovie b
+WHERE a.ctid < b.ctid AND a.actor_id = b.actor_id AND a.movie_id = b.movie_id;
+
+ALTER TABLE ActorMovie ADD PRIMARY KEY (actor_id, movie_id);
+
+CREATE OR REPLACE FUNCTION add_actors_to_movie(actor_ids integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO ActorMovie (actor_id, movie_id)
+    SELECT DISTINCT ids.actor_id, add_actors_to_movie.movie_id
+    FROM unnest(actor_ids) AS ids(actor_id);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION delete_actors_from_movie(actor_ids integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    DELETE FROM ActorMovie am
+    WHERE am.actor_id = ANY(actor_ids)
+    AND am.movie_id = delete_actors_from_movie.movie_id;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,12 @@
+DROP FUNCTION IF EXISTS add_actors_to_movie(integer[], varchar[], integer[], int);
+
+CREATE OR REPLACE FUNCTION add_actors_to_movie(actor_ids integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO ActorMovie (actor_id, movie_id)
+    SELECT DISTINCT ids.actor_id, add_actors_to_movie.movie_id
+    FROM unnest(actor_ids) AS ids(actor_id);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE ActorMovie DROP COLUMN billing_order;
+ALTER TABLE ActorMovie DROP COLUMN character_name;
@@ -0,0 +1,12 @@
+ALTER TABLE ActorMovie ADD COLUMN character_name varchar(150);
+ALTER TABLE ActorMovie ADD COLUMN billing_order int CHECK (billing_order > 0);
+
+DROP FUNCTION IF EXISTS add_actors_to_movie(integer[], int);
+
+CREATE OR REPLACE FUNCTION add_actors_to_movie(actor_ids integer[], characters varchar[], billing integer[], movie_id int) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO ActorMovie (actor_id, movie_id, character_name, billing_order)
+    SELECT cast_member.actor_id, add_actors_to_movie.movie_id, NULLIF(cast_member.character_name, ''), NULLIF(cast_member.billing_order, 0)
+    FROM unnest(actor_ids, characters, billing) AS cast_member(actor_id, character_name, billing_order);
+END;
+$$ LANGUAGE plpgsql;
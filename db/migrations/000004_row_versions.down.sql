@@ -0,0 +1,5 @@
+ALTER TABLE Actors DROP COLUMN updated_at;
+ALTER TABLE Actors DROP COLUMN version;
+
+ALTER TABLE Movies DROP COLUMN updated_at;
+ALTER TABLE Movies DROP COLUMN version;
@@ -0,0 +1,5 @@
+ALTER TABLE Movies ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE Movies ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
+
+ALTER TABLE Actors ADD COLUMN version int NOT NULL DEFAULT 1;
+ALTER TABLE Actors ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now();
@@ -0,0 +1,26 @@
+-- Writers take pg_advisory_xact_lock(hashtext('Audit')) before inserting,
+-- see LockAudit in internal/repository/audit.go, so that ids commit in
+-- order. That serializes all writes to the catalog, and batches taking it
+-- while holding row locks form deadlock cycles that only the retries of
+-- the Transactor recover from.
+CREATE TABLE Audit (
+    id bigserial primary key,
+    user_name varchar(150) not null,
+    created_at timestamptz not null default now(),
+    entity varchar(16) not null,
+    entity_id int not null,
+    operation varchar(16) not null,
+    before jsonb,
+    after jsonb
+);
+
+CREATE INDEX idx_audit_entity ON Audit(entity, entity_id, id);
+
+CREATE OR REPLACE FUNCTION audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_append_only BEFORE UPDATE OR DELETE ON Audit
+    FOR EACH ROW EXECUTE FUNCTION audit_append_only();
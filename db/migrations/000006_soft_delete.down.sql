@@ -0,0 +1,16 @@
+DELETE FROM ActorMovie am USING Movies m WHERE am.movie_id = m.id AND m.deleted_at IS NOT NULL;
+DELETE FROM ActorMovie am USING Actors a WHERE am.actor_id = a.id AND a.deleted_at IS NOT NULL;
+DELETE FROM Movies WHERE deleted_at IS NOT NULL;
+DELETE FROM Actors WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_actors_deleted_at;
+DROP INDEX IF EXISTS idx_movies_deleted_at;
+
+DROP INDEX IF EXISTS actors_names_live_key;
+ALTER TABLE Actors ADD CONSTRAINT actors_names_key UNIQUE (names);
+
+DROP INDEX IF EXISTS movies_title_live_key;
+ALTER TABLE Movies ADD CONSTRAINT movies_title_key UNIQUE (title);
+
+ALTER TABLE Actors DROP COLUMN deleted_at;
+ALTER TABLE Movies DROP COLUMN deleted_at;
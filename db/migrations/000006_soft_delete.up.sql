@@ -0,0 +1,13 @@
+ALTER TABLE Movies ADD COLUMN deleted_at timestamptz;
+ALTER TABLE Actors ADD COLUMN deleted_at timestamptz;
+
+-- Deleted rows keep their title or name until they are purged, so
+-- uniqueness only applies to live rows.
+ALTER TABLE Movies DROP CONSTRAINT movies_title_key;
+CREATE UNIQUE INDEX movies_title_live_key ON Movies(title) WHERE deleted_at IS NULL;
+
+ALTER TABLE Actors DROP CONSTRAINT actors_names_key;
+CREATE UNIQUE INDEX actors_names_live_key ON Actors(names) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_movies_deleted_at ON Movies(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_actors_deleted_at ON Actors(deleted_at) WHERE deleted_at IS NOT NULL;
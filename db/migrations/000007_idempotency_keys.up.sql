@@ -0,0 +1,15 @@
+-- Keys are scoped to the client sending them so that clients picking the
+-- same key do not see each other's responses.
+CREATE TABLE IdempotencyKeys (
+    client varchar(255) not null,
+    key varchar(255) not null,
+    request_hash char(64) not null,
+    -- status_code is NULL while the first request with the key is running.
+    status_code int,
+    content_type varchar(255),
+    body bytea,
+    expires_at timestamptz not null,
+    primary key (client, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON IdempotencyKeys(expires_at);
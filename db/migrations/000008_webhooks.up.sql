@@ -0,0 +1,25 @@
+CREATE TABLE Webhooks (
+    id serial primary key,
+    url text not null,
+    events text[] not null,
+    secret text not null,
+    created_at timestamptz not null default now()
+);
+
+-- WebhookDeliveries is the outbox: rows are written in the transaction of
+-- the change they announce and sent by the dispatcher afterwards.
+CREATE TABLE WebhookDeliveries (
+    id bigserial primary key,
+    webhook_id int not null references Webhooks(id) on delete cascade,
+    event_id bigint not null,
+    event_type varchar(32) not null,
+    payload jsonb not null,
+    status varchar(16) not null default 'pending',
+    attempts int not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    last_error text not null default '',
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON WebhookDeliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON WebhookDeliveries(webhook_id, id);
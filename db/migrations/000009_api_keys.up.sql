@@ -0,0 +1,12 @@
+CREATE TABLE ApiKeys (
+    id serial primary key,
+    name varchar(150) not null,
+    -- prefix is the start of the key, shown to tell keys apart.
+    prefix varchar(16) not null,
+    key_hash char(64) not null unique,
+    scopes text[] not null,
+    expires_at timestamptz,
+    created_at timestamptz not null default now(),
+    last_used_at timestamptz,
+    revoked_at timestamptz
+);
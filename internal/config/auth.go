@@ -0,0 +1,21 @@
+package config
+
+// AuthConfig tells whether every request must present an API key, which
+// it must unless Required is turned off. Keys that are presented are
+// checked either way, requests without one are let through while Required
+// is off.
+type AuthConfig struct {
+	Required bool
+}
+
+func GetAuthConfig() (*AuthConfig, error) {
+
+	config := &AuthConfig{Required: true}
+	err := unmarshal("auth", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	CacheNone   = "none"
+	CacheMemory = "memory"
+	CacheRedis  = "redis"
+
+	defaultCacheSize = 1000
+	defaultCacheTTL  = 30 * time.Second
+)
+
+// CacheConfig selects where movie list and search results are cached: not
+// at all, in process memory keeping up to size results, or in Redis, which
+// instances share. Results expire after ttl at the latest.
+type CacheConfig struct {
+	Backend string
+	Size    int
+	TTL     time.Duration
+	Redis   RedisConfig
+}
+
+type RedisConfig struct {
+	Addr     string
+	Password string `secret:"true"`
+	DB       int
+	Prefix   string
+}
+
+func GetCacheConfig() (*CacheConfig, error) {
+
+	config := &CacheConfig{
+		Backend: CacheNone,
+		Size:    defaultCacheSize,
+		TTL:     defaultCacheTTL,
+		Redis:   RedisConfig{Addr: "localhost:6379", Prefix: "filmoteka:"},
+	}
+	err := unmarshal("cache", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Backend {
+	case CacheNone, CacheMemory, CacheRedis:
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q, use none, memory or redis", config.Backend)
+	}
+
+	if config.Size <= 0 || config.TTL <= 0 {
+		return nil, fmt.Errorf("cache size and ttl must be positive")
+	}
+
+	return config, nil
+}
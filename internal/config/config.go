@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the values of secrets in Print.
+const redacted = "REDACTED"
+
+// Config is the whole configuration of the server. Postgres is only read
+// when it is the storage. Fields tagged secret are redacted by Print.
+type Config struct {
+	Storage     string
+	HTTP        *HTTPConfig        `mapstructure:"http"`
+	Log         *LogConfig         `mapstructure:"log"`
+	Tracing     *TracingConfig     `mapstructure:"tracing"`
+	Postgres    *DBConfig          `mapstructure:"postgres"`
+	Cache       *CacheConfig       `mapstructure:"cache"`
+	Purge       *PurgeConfig       `mapstructure:"purge"`
+	Idempotency *IdempotencyConfig `mapstructure:"idempotency"`
+	Webhooks    *WebhookConfig     `mapstructure:"webhooks"`
+	GRPC        *GRPCConfig        `mapstructure:"grpc"`
+	RateLimit   *RateLimitConfig   `mapstructure:"ratelimit"`
+	Auth        *AuthConfig        `mapstructure:"auth"`
+}
+
+// Load reads and checks every section, so that a mistake anywhere stops the
+// server before it starts. Errors name the section at fault.
+func Load() (*Config, error) {
+
+	config := &Config{}
+	var err error
+
+	if err := checkSections(); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	if config.Storage, err = GetStorage(); err != nil {
+		return nil, fmt.Errorf("config storage: %w", err)
+	}
+
+	sections := []struct {
+		name string
+		load func() error
+	}{
+		{"http", func() (err error) { config.HTTP, err = GetHTTPConfig(); return }},
+		{"log", func() (err error) { config.Log, err = GetLogConfig(); return }},
+		{"tracing", func() (err error) { config.Tracing, err = GetTracingConfig(); return }},
+		{"postgres", func() (err error) {
+			if config.Storage == StoragePostgres {
+				config.Postgres, err = GetDBConfig()
+			}
+			return
+		}},
+		{"cache", func() (err error) { config.Cache, err = GetCacheConfig(); return }},
+		{"purge", func() (err error) { config.Purge, err = GetPurgeConfig(); return }},
+		{"idempotency", func() (err error) { config.Idempotency, err = GetIdempotencyConfig(); return }},
+		{"webhooks", func() (err error) { config.Webhooks, err = GetWebhookConfig(); return }},
+		{"grpc", func() (err error) { config.GRPC, err = GetGRPCConfig(); return }},
+		{"ratelimit", func() (err error) { config.RateLimit, err = GetRateLimitConfig(); return }},
+		{"auth", func() (err error) { config.Auth, err = GetAuthConfig(); return }},
+	}
+
+	for _, section := range sections {
+		if err := section.load(); err != nil {
+			return nil, fmt.Errorf("config %s: %w", section.name, err)
+		}
+	}
+
+	return config, nil
+}
+
+// Print writes config as YAML, with defaults filled in and secrets
+// redacted.
+func Print(w io.Writer, config *Config) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(printable(reflect.ValueOf(config))); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// printable turns value into maps keyed like the config file, leaving out
+// sections that were not read.
+func printable(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	if duration, ok := value.Interface().(time.Duration); ok {
+		return duration.String()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return value.Interface()
+	}
+
+	fields := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldValue := value.Type().Field(i), value.Field(i)
+
+		switch {
+		case fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil():
+		case field.Tag.Get("secret") != "" && fieldValue.Kind() == reflect.Slice:
+			secrets := make([]string, fieldValue.Len())
+			for i := range secrets {
+				secrets[i] = redacted
+			}
+			fields[configKey(field)] = secrets
+		case field.Tag.Get("secret") != "" && !fieldValue.IsZero():
+			fields[configKey(field)] = redacted
+		default:
+			fields[configKey(field)] = printable(fieldValue)
+		}
+	}
+	return fields
+}
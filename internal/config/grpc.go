@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// GRPCConfig sets the port of the gRPC server and the bearer token calls
+// must present, calls are refused while it is empty.
+type GRPCConfig struct {
+	Port  string
+	Token string `secret:"true"`
+}
+
+func GetGRPCConfig() (*GRPCConfig, error) {
+
+	config := &GRPCConfig{Port: "3001"}
+	err := unmarshal("grpc", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if port, err := strconv.Atoi(config.Port); err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("grpc port %q is not a port number", config.Port)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// HTTPConfig sets the port the HTTP API is served on.
+type HTTPConfig struct {
+	Port string
+}
+
+func GetHTTPConfig() (*HTTPConfig, error) {
+
+	config := &HTTPConfig{Port: "8080"}
+	err := unmarshal("http", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if port, err := strconv.Atoi(config.Port); err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("http port %q is not a port number", config.Port)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// IdempotencyConfig tells how long responses to requests with an
+// Idempotency-Key header are kept for replay.
+type IdempotencyConfig struct {
+	TTL time.Duration
+}
+
+func GetIdempotencyConfig() (*IdempotencyConfig, error) {
+
+	config := &IdempotencyConfig{TTL: defaultIdempotencyTTL}
+	err := unmarshal("idempotency", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.TTL <= 0 {
+		return nil, fmt.Errorf("idempotency ttl must be positive")
+	}
+
+	return config, nil
+}
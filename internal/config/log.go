@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LogConfig sets the lowest level logged, e.g. debug or info, and whether
+// lines are written as text or json.
+type LogConfig struct {
+	Level  string
+	Format string
+}
+
+func GetLogConfig() (*LogConfig, error) {
+
+	config := &LogConfig{Level: "info", Format: "text"}
+	err := unmarshal("log", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := log.ParseLevel(config.Level); err != nil {
+		return nil, err
+	}
+
+	if config.Format != "text" && config.Format != "json" {
+		return nil, fmt.Errorf("unknown log format %q, use text or json", config.Format)
+	}
+
+	return config, nil
+}
@@ -1,25 +1,104 @@
 package config
 
 import (
-	"github.com/spf13/viper"
+	"fmt"
+	"time"
 )
 
+const (
+	defaultDBSSLMode           = "prefer"
+	defaultDBMaxOpenConns      = 20
+	defaultDBMaxIdleConns      = 5
+	defaultDBConnMaxLifetime   = 30 * time.Minute
+	defaultDBConnectAttempts   = 10
+	defaultDBConnectBackoff    = 500 * time.Millisecond
+	defaultDBConnectMaxBackoff = 5 * time.Second
+	defaultDBConnectTimeout    = 5 * time.Second
+	defaultDBReplicaInterval   = 5 * time.Second
+	defaultDBReplicaStickiness = 5 * time.Second
+)
+
+// DBConfig tells how to reach Postgres and how big the connection pool
+// is. A statement running longer than statement_timeout is cancelled by
+// the server, 0 lets it run. Connecting at startup is tried up to
+// connect_attempts times, each bounded by connect_timeout, waiting
+// connect_backoff after the first failure, doubling up to
+// connect_max_backoff.
+//
+// Replicas are connection strings of read replicas that list and search
+// queries go to, checked every replica_check_interval. In the environment
+// they are separated by commas, or given as a JSON array. A user's reads go to
+// the primary for replica_stickiness after the user wrote.
 type DBConfig struct {
-	Name     string
-	Password string
-	Db       string
-	Host     string
-	Port     string
+	Name              string
+	Password          string `secret:"true"`
+	Db                string
+	Host              string
+	Port              string
+	SSLMode           string        `mapstructure:"sslmode"`
+	MaxOpenConns      int           `mapstructure:"max_open_conns"`
+	MaxIdleConns      int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	StatementTimeout  time.Duration `mapstructure:"statement_timeout"`
+	ConnectTimeout    time.Duration `mapstructure:"connect_timeout"`
+	ConnectAttempts   int           `mapstructure:"connect_attempts"`
+	ConnectBackoff    time.Duration `mapstructure:"connect_backoff"`
+	ConnectMaxBackoff time.Duration `mapstructure:"connect_max_backoff"`
+
+	Replicas             []string      `secret:"true"`
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval"`
+	ReplicaStickiness    time.Duration `mapstructure:"replica_stickiness"`
+}
+
+// sslModes are the sslmode values libpq understands.
+var sslModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
 }
 
 func GetDBConfig() (*DBConfig, error) {
 
-	config := &DBConfig{}
-	err := viper.UnmarshalKey("postgres", config)
+	config := &DBConfig{
+		SSLMode:           defaultDBSSLMode,
+		MaxOpenConns:      defaultDBMaxOpenConns,
+		MaxIdleConns:      defaultDBMaxIdleConns,
+		ConnMaxLifetime:   defaultDBConnMaxLifetime,
+		ConnectTimeout:    defaultDBConnectTimeout,
+		ConnectAttempts:   defaultDBConnectAttempts,
+		ConnectBackoff:    defaultDBConnectBackoff,
+		ConnectMaxBackoff: defaultDBConnectMaxBackoff,
+
+		ReplicaCheckInterval: defaultDBReplicaInterval,
+		ReplicaStickiness:    defaultDBReplicaStickiness,
+	}
+	err := unmarshal("postgres", config)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if config.Host == "" || config.Port == "" || config.Name == "" || config.Db == "" {
+		return nil, fmt.Errorf("postgres host, port, name and db must be set")
+	}
+
+	if !sslModes[config.SSLMode] {
+		return nil, fmt.Errorf("unknown postgres sslmode %q", config.SSLMode)
+	}
+
+	if config.MaxOpenConns <= 0 || config.MaxIdleConns < 0 || config.MaxIdleConns > config.MaxOpenConns {
+		return nil, fmt.Errorf("postgres max_open_conns must be positive and max_idle_conns between 0 and max_open_conns")
+	}
+
+	if config.ConnMaxLifetime < 0 || config.StatementTimeout < 0 {
+		return nil, fmt.Errorf("postgres conn_max_lifetime and statement_timeout must not be negative")
+	}
+
+	if config.ConnectTimeout <= 0 || config.ConnectAttempts <= 0 || config.ConnectBackoff <= 0 || config.ConnectMaxBackoff < config.ConnectBackoff {
+		return nil, fmt.Errorf("postgres connect_timeout, connect_attempts and connect_backoff must be positive and connect_max_backoff at least connect_backoff")
+	}
+
+	if config.ReplicaCheckInterval <= 0 || config.ReplicaStickiness < 0 {
+		return nil, fmt.Errorf("postgres replica_check_interval must be positive and replica_stickiness not negative")
+	}
+
 	return config, nil
 }
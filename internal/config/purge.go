@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultPurgeRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// PurgeConfig tells how long deleted movies and actors can be restored and
+// how often the ones past that are removed for good.
+type PurgeConfig struct {
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func GetPurgeConfig() (*PurgeConfig, error) {
+
+	config := &PurgeConfig{Retention: defaultPurgeRetention, Interval: defaultPurgeInterval}
+	err := unmarshal("purge", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Retention <= 0 || config.Interval <= 0 {
+		return nil, fmt.Errorf("purge retention and interval must be positive")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import "fmt"
+
+// Limit is a token bucket: Rate requests per second on average with bursts
+// of up to Burst requests.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig holds a limit per route class and per client, and the
+// number of requests an API key may make per UTC day, 0 for no quota.
+type RateLimitConfig struct {
+	Enabled    bool
+	Read       Limit
+	Search     Limit
+	Write      Limit
+	DailyQuota int `mapstructure:"daily_quota"`
+}
+
+func GetRateLimitConfig() (*RateLimitConfig, error) {
+
+	config := &RateLimitConfig{
+		Read:   Limit{Rate: 20, Burst: 40},
+		Search: Limit{Rate: 2, Burst: 10},
+		Write:  Limit{Rate: 5, Burst: 10},
+	}
+	err := unmarshal("ratelimit", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, limit := range []Limit{config.Read, config.Search, config.Write} {
+		if limit.Rate <= 0 || limit.Burst <= 0 {
+			return nil, fmt.Errorf("rate limit rate and burst must be positive")
+		}
+	}
+
+	if config.DailyQuota < 0 {
+		return nil, fmt.Errorf("daily quota must not be negative")
+	}
+
+	return config, nil
+}
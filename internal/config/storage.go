@@ -0,0 +1,28 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// GetStorage tells which repositories the server runs on, Postgres unless
+// configured otherwise.
+func GetStorage() (string, error) {
+
+	storage := viper.GetString("storage")
+
+	switch storage {
+	case "":
+		return StoragePostgres, nil
+	case StoragePostgres, StorageMemory:
+		return storage, nil
+	}
+
+	return "", fmt.Errorf("unknown storage %q", storage)
+}
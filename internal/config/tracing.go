@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// TracingConfig chooses where spans are exported: nowhere, to stdout or to
+// an OTLP/HTTP collector at Endpoint. SampleRatio is the share of traces
+// started here that are recorded, traces started by callers follow their
+// decision.
+type TracingConfig struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	ServiceName string  `mapstructure:"service_name"`
+}
+
+func GetTracingConfig() (*TracingConfig, error) {
+
+	config := &TracingConfig{Exporter: TracingNone, SampleRatio: 1, ServiceName: "filmoteka"}
+	err := unmarshal("tracing", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Exporter {
+	case TracingNone, TracingStdout, TracingOTLP:
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, use none, stdout or otlp", config.Exporter)
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// envPrefix starts the names of the environment variables that override
+// config keys.
+const envPrefix = "FILMOTEKA_"
+
+// SetupViper reads the config file at path, or configs/config.yaml when
+// path is empty, and applies the overrides of the environment. Without a
+// path the file may be missing and everything comes from the environment.
+func SetupViper(path string) error {
+
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath("configs")
+		viper.SetConfigName("config")
+	}
+
+	var notFound viper.ConfigFileNotFoundError
+	if err := viper.ReadInConfig(); err != nil && (path != "" || !errors.As(err, &notFound)) {
+		return err
+	}
+
+	return applyEnv()
+}
+
+// applyEnv overrides every config key set in the environment. The variable
+// of a key is envPrefix followed by the key in upper case with dots turned
+// into underscores, FILMOTEKA_POSTGRES_PASSWORD for postgres.password. The
+// same name ending in _FILE names a file holding the value instead, the way
+// Docker and Kubernetes secrets are mounted. Lists are separated by commas,
+// or given as a JSON array of strings when an item holds a comma.
+func applyEnv() error {
+
+	overrides := map[string]interface{}{}
+	for key, valueType := range configKeys("", reflect.TypeOf(Config{})) {
+		name := envName(key)
+		value, ok := os.LookupEnv(name)
+
+		if file, fromFile := os.LookupEnv(name + "_FILE"); fromFile {
+			if ok {
+				return fmt.Errorf("both %s and %s_FILE are set", name, name)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("%s_FILE: %w", name, err)
+			}
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+		}
+
+		if !ok {
+			continue
+		}
+
+		var override interface{} = value
+		if valueType.Kind() == reflect.Slice && strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var list []string
+			if err := json.Unmarshal([]byte(value), &list); err != nil {
+				return fmt.Errorf("%s is not a JSON array of strings: %w", name, err)
+			}
+			override = list
+		}
+
+		section := overrides
+		path := strings.Split(key, ".")
+		for _, name := range path[:len(path)-1] {
+			if _, ok := section[name].(map[string]interface{}); !ok {
+				section[name] = map[string]interface{}{}
+			}
+			section = section[name].(map[string]interface{})
+		}
+		section[path[len(path)-1]] = override
+	}
+
+	return viper.MergeConfigMap(overrides)
+}
+
+// envName returns the environment variable overriding key.
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// configKeys returns the type of every value in the config struct t, keyed
+// by its key under prefix.
+func configKeys(prefix string, t reflect.Type) map[string]reflect.Type {
+	keys := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + configKey(field)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct {
+			maps.Copy(keys, configKeys(key+".", fieldType))
+		} else {
+			keys[key] = fieldType
+		}
+	}
+	return keys
+}
+
+// configKey returns the key field is read from.
+func configKey(field reflect.StructField) string {
+	if key := field.Tag.Get("mapstructure"); key != "" {
+		return key
+	}
+	return strings.ToLower(field.Name)
+}
+
+// unmarshal reads the section key into config, failing on keys config has
+// no field for, which usually are typos.
+func unmarshal(key string, config interface{}) error {
+
+	var metadata mapstructure.Metadata
+	err := viper.UnmarshalKey(key, config, func(decoder *mapstructure.DecoderConfig) {
+		decoder.Metadata = &metadata
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(metadata.Unused) > 0 {
+		sort.Strings(metadata.Unused)
+		return fmt.Errorf("unknown keys %s", strings.Join(metadata.Unused, ", "))
+	}
+
+	return nil
+}
+
+// checkSections fails on top-level keys Config has no field for, which
+// would otherwise be ignored.
+func checkSections() error {
+
+	known := map[string]bool{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		known[configKey(t.Field(i))] = true
+	}
+
+	var unknown []string
+	for key := range viper.AllSettings() {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown sections %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupViper reads yaml as the config file, with the environment of the
+// test applied over it.
+func setupViper(t *testing.T, yaml string) error {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return SetupViper(path)
+}
+
+func writeSecret(t *testing.T, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyEnv(t *testing.T) {
+
+	t.Setenv("FILMOTEKA_STORAGE", "memory")
+	t.Setenv("FILMOTEKA_GRPC_PORT", "4001")
+	t.Setenv("FILMOTEKA_GRPC_TOKEN_FILE", writeSecret(t, "s3cret\n"))
+	t.Setenv("FILMOTEKA_RATELIMIT_DAILY_QUOTA", "100")
+
+	if err := setupViper(t, "grpc:\n  port: 3001\n  token: from-file\n"); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Storage != StorageMemory {
+		t.Errorf("storage = %q, want it from the environment", config.Storage)
+	}
+	if config.GRPC.Port != "4001" {
+		t.Errorf("grpc port = %q, want the environment over the file", config.GRPC.Port)
+	}
+	if config.GRPC.Token != "s3cret" {
+		t.Errorf("grpc token = %q, want the secret file without its newline", config.GRPC.Token)
+	}
+	if config.RateLimit.DailyQuota != 100 {
+		t.Errorf("daily quota = %d, want 100", config.RateLimit.DailyQuota)
+	}
+}
+
+func TestApplyEnvLists(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"comma separated", "host=replica1 user=filmoteka,host=replica2 user=filmoteka", []string{"host=replica1 user=filmoteka", "host=replica2 user=filmoteka"}},
+		{"JSON array", `["postgres://filmoteka@replica1,replica2/filmoteka", "host=replica3"]`, []string{"postgres://filmoteka@replica1,replica2/filmoteka", "host=replica3"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("FILMOTEKA_POSTGRES_REPLICAS", test.value)
+
+			err := setupViper(t, "postgres:\n  host: localhost\n  port: 5432\n  name: filmoteka\n  db: filmoteka\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(config.Postgres.Replicas, test.want) {
+				t.Errorf("replicas = %q, want %q", config.Postgres.Replicas, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"value and file", map[string]string{
+			"FILMOTEKA_GRPC_TOKEN":      "s3cret",
+			"FILMOTEKA_GRPC_TOKEN_FILE": "/run/secrets/token",
+		}, "both FILMOTEKA_GRPC_TOKEN and FILMOTEKA_GRPC_TOKEN_FILE are set"},
+		{"missing file", map[string]string{
+			"FILMOTEKA_POSTGRES_PASSWORD_FILE": "/nonexistent/password",
+		}, "FILMOTEKA_POSTGRES_PASSWORD_FILE: "},
+		{"broken list", map[string]string{
+			"FILMOTEKA_POSTGRES_REPLICAS": `["host=replica`,
+		}, "FILMOTEKA_POSTGRES_REPLICAS is not a JSON array of strings"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			err := setupViper(t, "storage: memory\n")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+
+	t.Setenv("FILMOTEKA_GRPC_TOKEN", "s3cret")
+	t.Setenv("FILMOTEKA_POSTGRES_PASSWORD", "hunter2")
+
+	err := setupViper(t, `storage: postgres
+postgres:
+  host: localhost
+  port: 5432
+  name: filmoteka
+  db: filmoteka
+  replicas:
+    - host=replica user=filmoteka password=hunter3
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Print(&out, config); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"s3cret", "hunter2", "hunter3"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("printed config shows %q:\n%s", secret, out.String())
+		}
+	}
+	for _, want := range []string{"token: " + redacted, "password: " + redacted, "- " + redacted, "host: localhost"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printed config lacks %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestLoadUnknownKeys(t *testing.T) {
+
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"section", "storage: memory\ngrcp:\n  port: 3001\n", "config: unknown sections grcp"},
+		{"key", "storage: memory\ngrpc:\n  prot: 3001\n", "config grpc: unknown keys prot"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := setupViper(t, test.yaml); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load()
+			if err == nil || err.Error() != test.want {
+				t.Errorf("error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthRequiredByDefault(t *testing.T) {
+
+	if err := setupViper(t, "storage: memory\n"); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !config.Auth.Required {
+		t.Error("auth.required is off without being turned off")
+	}
+
+	t.Setenv("FILMOTEKA_AUTH_REQUIRED", "false")
+	if err := setupViper(t, "storage: memory\n"); err != nil {
+		t.Fatal(err)
+	}
+	if config, err = Load(); err != nil {
+		t.Fatal(err)
+	}
+	if config.Auth.Required {
+		t.Error("FILMOTEKA_AUTH_REQUIRED=false left keys required")
+	}
+}
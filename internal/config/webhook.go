@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	defaultWebhookInterval    = time.Second
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultWebhookBackoff     = 30 * time.Second
+	defaultWebhookMaxBackoff  = time.Hour
+	defaultWebhookMaxAttempts = 10
+)
+
+// WebhookConfig tells how often the outbox is checked for due deliveries,
+// how long a partner has to answer, and how failed deliveries are retried:
+// after backoff, doubling up to max_backoff, until max_attempts failed and
+// the delivery is dead.
+type WebhookConfig struct {
+	Interval    time.Duration
+	Timeout     time.Duration
+	Backoff     time.Duration
+	MaxBackoff  time.Duration `mapstructure:"max_backoff"`
+	MaxAttempts int           `mapstructure:"max_attempts"`
+}
+
+func GetWebhookConfig() (*WebhookConfig, error) {
+
+	config := &WebhookConfig{
+		Interval:    defaultWebhookInterval,
+		Timeout:     defaultWebhookTimeout,
+		Backoff:     defaultWebhookBackoff,
+		MaxBackoff:  defaultWebhookMaxBackoff,
+		MaxAttempts: defaultWebhookMaxAttempts,
+	}
+	err := unmarshal("webhooks", config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Interval <= 0 || config.Timeout <= 0 || config.Backoff <= 0 || config.MaxBackoff < config.Backoff || config.MaxAttempts <= 0 {
+		return nil, fmt.Errorf("webhook interval, timeout, backoff and max_attempts must be positive and max_backoff at least backoff")
+	}
+
+	return config, nil
+}
@@ -5,5 +5,7 @@ type Actor struct {
 	Name   string `json:"name" validate:"required"`
 	Sex    rune   `json:"sex" validate:"required"`
 	Bd     string `json:"bd" validate:"required"`
-	Movies []int
+	Movies []int  `json:"movies"`
+	// Version is bumped on every change of the actor.
+	Version int `json:"-"`
 }
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Scopes an API key can be granted. ScopeAdmin grants every other scope.
+const (
+	ScopeMoviesRead  = "movies:read"
+	ScopeMoviesWrite = "movies:write"
+	ScopeActorsRead  = "actors:read"
+	ScopeActorsWrite = "actors:write"
+	ScopeAdmin       = "admin"
+)
+
+// Scopes lists every scope.
+var Scopes = []string{ScopeMoviesRead, ScopeMoviesWrite, ScopeActorsRead, ScopeActorsWrite, ScopeAdmin}
+
+// APIKey is a long-lived credential of a service. Only a hash of the key is
+// stored, Key is set once when the key is issued.
+type APIKey struct {
+	Id     int      `json:"id"`
+	Name   string   `json:"name"`
+	Key    string   `json:"key,omitempty"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is nil for keys that do not expire.
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// HasScope reports whether the key grants scope.
+func (key *APIKey) HasScope(scope string) bool {
+	return slices.Contains(key.Scopes, scope) || slices.Contains(key.Scopes, ScopeAdmin)
+}
+
+type apiKeyKey struct{}
+
+// WithAPIKey attaches the API key a request was authenticated with to ctx.
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey{}, key)
+}
+
+// APIKeyFrom returns the API key attached to ctx, or nil.
+func APIKeyFrom(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyKey{}).(*APIKey)
+	return key
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditMovie = "movie"
+	AuditActor = "actor"
+
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditLink    = "link"
+	AuditUnlink  = "unlink"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+
+	// AnonymousUser is recorded when a change is made without a known user.
+	AnonymousUser = "anonymous"
+	// SystemUser is recorded for changes made by background jobs.
+	SystemUser = "system"
+	// GRPCUser is recorded for changes made through the gRPC API, whose
+	// callers all present the same token.
+	GRPCUser = "grpc"
+)
+
+// AuditEntry records a single change of a movie or an actor. Before is null
+// for creations and After is null for deletions.
+type AuditEntry struct {
+	Id        int64           `json:"id"`
+	User      string          `json:"user"`
+	At        time.Time       `json:"at"`
+	Entity    string          `json:"entity"`
+	EntityId  int             `json:"entity_id"`
+	Operation string          `json:"operation"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+}
+
+// AuditFilter selects a page of the audit log. Zero fields match everything,
+// After is the id of the last entry of the previous page.
+type AuditFilter struct {
+	Entity   string
+	EntityId int
+	After    int64
+	Limit    int
+}
+
+// AuditPage is a page of the audit log, Next is empty on the last page.
+type AuditPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	Next    int64         `json:"next,omitempty"`
+}
+
+type userKey struct{}
+
+// WithUser attaches the user making a request to ctx.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user attached to ctx, AnonymousUser if there is none.
+func UserFrom(ctx context.Context) string {
+	if user, ok := ctx.Value(userKey{}).(string); ok && user != "" {
+		return user
+	}
+	return AnonymousUser
+}
+
+// AuditSnapshot renders an entity for AuditEntry.Before and After, nil stays
+// null.
+func AuditSnapshot(entity interface{}) (json.RawMessage, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	return json.Marshal(entity)
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Operations a batch can contain.
+const (
+	BatchCreateMovie = "create_movie"
+	BatchUpdateMovie = "update_movie"
+	BatchDeleteMovie = "delete_movie"
+	BatchCreateActor = "create_actor"
+	BatchUpdateActor = "update_actor"
+	BatchDeleteActor = "delete_actor"
+	BatchLink        = "link"
+	BatchUnlink      = "unlink"
+)
+
+// BatchRef names a row either by id or by the ref of the operation that
+// created it earlier in the same batch. In JSON it is a number or a string.
+type BatchRef struct {
+	Id  int
+	Ref string
+}
+
+func (ref *BatchRef) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &ref.Ref)
+	}
+	return json.Unmarshal(data, &ref.Id)
+}
+
+func (ref BatchRef) MarshalJSON() ([]byte, error) {
+	if ref.Ref != "" {
+		return json.Marshal(ref.Ref)
+	}
+	return json.Marshal(ref.Id)
+}
+
+// BatchCastMember is a CastMember whose actor may be a ref.
+type BatchCastMember struct {
+	ActorId   BatchRef `json:"actor_id"`
+	ActorName string   `json:"actor_name,omitempty"`
+	Character string   `json:"character,omitempty"`
+	Billing   int      `json:"billing,omitempty"`
+}
+
+// BatchOperation is a single step of a batch. Id is the row an update,
+// delete, link or unlink applies to; for link and unlink it is the movie.
+// Ref names the row a create operation adds so later steps can use it.
+type BatchOperation struct {
+	Op      string            `json:"op"`
+	Ref     string            `json:"ref,omitempty"`
+	Id      BatchRef          `json:"id"`
+	Version int               `json:"version,omitempty"`
+	Movie   *Movie            `json:"movie,omitempty"`
+	Actor   *Actor            `json:"actor,omitempty"`
+	Column  string            `json:"column,omitempty"`
+	Value   interface{}       `json:"value,omitempty"`
+	Actors  []BatchCastMember `json:"actors,omitempty"`
+}
+
+// BatchResult reports the row an operation applied to.
+type BatchResult struct {
+	Op  string `json:"op"`
+	Ref string `json:"ref,omitempty"`
+	Id  int    `json:"id"`
+}
+
+// BatchError wraps the error of the operation that aborted a batch.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (err *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %s", err.Index, err.Err)
+}
+
+func (err *BatchError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,27 @@
+package core
+
+// CastMember references an actor of a movie either by id or by exact name.
+// Character and Billing are optional, a zero Billing means the actor is not
+// billed.
+type CastMember struct {
+	ActorId   int    `json:"actor_id,omitempty"`
+	ActorName string `json:"actor_name,omitempty"`
+	Character string `json:"character,omitempty"`
+	Billing   int    `json:"billing,omitempty"`
+}
+
+// Valid reports whether the member names exactly one actor and has a
+// non-negative billing order.
+func (member CastMember) Valid() bool {
+	return (member.ActorId != 0) != (member.ActorName != "") && member.Billing >= 0
+}
+
+// CastActor is an actor as credited in a movie.
+type CastActor struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Sex       rune   `json:"sex"`
+	Bd        string `json:"bd"`
+	Character string `json:"character,omitempty"`
+	Billing   int    `json:"billing,omitempty"`
+}
@@ -0,0 +1,18 @@
+package core
+
+import "context"
+
+type sharedReadKey struct{}
+
+// WithSharedRead marks the reads made with ctx as serving every user, like
+// filling a cache does, so that they must see the latest write of anyone
+// and not only those of the user of ctx.
+func WithSharedRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sharedReadKey{}, true)
+}
+
+// IsSharedRead tells whether ctx was marked by WithSharedRead.
+func IsSharedRead(ctx context.Context) bool {
+	shared, _ := ctx.Value(sharedReadKey{}).(bool)
+	return shared
+}
@@ -3,11 +3,17 @@ package core
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type Info struct {
 	Msg        string
 	StatusCode int
+	// Ids and Names list every offending id or name when an operation on
+	// several actors fails.
+	Ids   []int
+	Names []string
 }
 
 type MyError struct {
@@ -19,18 +25,142 @@ func (err *MyError) Error() string {
 	return fmt.Sprintf("message:'%s'", err.Inf.Msg)
 }
 
+func idsMsg(msg string, ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return msg + ": " + strings.Join(parts, ", ")
+}
+
 func NewErrActorAlreadyExists() *MyError {
-	return &MyError{Type: "ErrActorAlreadyExists", Inf: Info{Msg: "actor already exists in database", StatusCode: http.StatusBadRequest}}
+	return &MyError{Type: "ErrActorAlreadyExists", Inf: Info{Msg: "actor already exists in database", StatusCode: http.StatusConflict}}
 }
 
 func NewErrActorDoesNotExist() *MyError {
-	return &MyError{Type: "ErrActorDoesNotExist", Inf: Info{Msg: "actor with this id does not exists", StatusCode: http.StatusBadRequest}}
+	return &MyError{Type: "ErrActorDoesNotExist", Inf: Info{Msg: "actor with this id does not exists", StatusCode: http.StatusNotFound}}
 }
 
 func NewErrMovieAlreadyExists() *MyError {
-	return &MyError{Type: "ErrActorAlreadyExists", Inf: Info{Msg: "movie already exists in database", StatusCode: http.StatusBadRequest}}
+	return &MyError{Type: "ErrMovieAlreadyExists", Inf: Info{Msg: "movie already exists in database", StatusCode: http.StatusConflict}}
 }
 
 func NewErrMovieDoesNotExist() *MyError {
-	return &MyError{Type: "ErrMovieDoesNotExist", Inf: Info{Msg: "movie with this id does not exists", StatusCode: http.StatusBadRequest}}
+	return &MyError{Type: "ErrMovieDoesNotExist", Inf: Info{Msg: "movie with this id does not exists", StatusCode: http.StatusNotFound}}
+}
+
+func NewErrUnknownSorting() *MyError {
+	return &MyError{Type: "ErrUnknownSorting", Inf: Info{Msg: "unknown sorting, use one of rating, title, release", StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrUnknownColumn() *MyError {
+	return &MyError{Type: "ErrUnknownColumn", Inf: Info{Msg: "column can not be updated or does not exist", StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrBadRequest() *MyError {
+	return &MyError{Type: "ErrBadRequest", Inf: Info{Msg: "could not parse request", StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrInternal() *MyError {
+	return &MyError{Type: "ErrInternal", Inf: Info{Msg: "Internal server error", StatusCode: http.StatusInternalServerError}}
+}
+
+func NewErrActorsDoNotExist(ids []int) *MyError {
+	return &MyError{Type: "ErrActorDoesNotExist", Inf: Info{Msg: idsMsg("actors with these ids do not exist", ids), StatusCode: http.StatusNotFound, Ids: ids}}
+}
+
+func NewErrActorsAlreadyLinked(ids []int) *MyError {
+	return &MyError{Type: "ErrActorAlreadyLinked", Inf: Info{Msg: idsMsg("actors are already linked to the movie", ids), StatusCode: http.StatusConflict, Ids: ids}}
+}
+
+func NewErrActorsNotLinked(ids []int) *MyError {
+	return &MyError{Type: "ErrActorNotLinked", Inf: Info{Msg: idsMsg("actors are not linked to the movie", ids), StatusCode: http.StatusNotFound, Ids: ids}}
+}
+
+func NewErrActorNamesDoNotExist(names []string) *MyError {
+	return &MyError{Type: "ErrActorDoesNotExist", Inf: Info{Msg: "actors with these names do not exist: " + strings.Join(names, ", "), StatusCode: http.StatusNotFound, Names: names}}
+}
+
+func NewErrUnknownInclude(name string) *MyError {
+	return &MyError{Type: "ErrUnknownInclude", Inf: Info{Msg: "relation can not be included: " + name, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrVersionMismatch() *MyError {
+	return &MyError{Type: "ErrVersionMismatch", Inf: Info{Msg: "resource was modified, reload it and try again", StatusCode: http.StatusPreconditionFailed}}
+}
+
+func NewErrUnknownEntity(name string) *MyError {
+	return &MyError{Type: "ErrUnknownEntity", Inf: Info{Msg: "unknown entity, use movie or actor: " + name, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrIdempotencyKeyReused() *MyError {
+	return &MyError{Type: "ErrIdempotencyKeyReused", Inf: Info{Msg: "idempotency key was already used for a different request", StatusCode: http.StatusUnprocessableEntity}}
+}
+
+func NewErrIdempotencyKeyInUse() *MyError {
+	return &MyError{Type: "ErrIdempotencyKeyInUse", Inf: Info{Msg: "a request with this idempotency key is still in progress", StatusCode: http.StatusConflict}}
+}
+
+func NewErrUnknownOperation(op string) *MyError {
+	return &MyError{Type: "ErrUnknownOperation", Inf: Info{Msg: "unknown batch operation: " + op, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrUnknownRef(ref string) *MyError {
+	return &MyError{Type: "ErrUnknownRef", Inf: Info{Msg: "ref is not defined by an earlier operation: " + ref, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrDuplicateRef(ref string) *MyError {
+	return &MyError{Type: "ErrDuplicateRef", Inf: Info{Msg: "ref is already defined by an earlier operation: " + ref, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrBatchTooLarge(max int) *MyError {
+	return &MyError{Type: "ErrBatchTooLarge", Inf: Info{Msg: "batch must contain between 1 and " + strconv.Itoa(max) + " operations", StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrWebhookDoesNotExist() *MyError {
+	return &MyError{Type: "ErrWebhookDoesNotExist", Inf: Info{Msg: "webhook with this id does not exists", StatusCode: http.StatusNotFound}}
+}
+
+func NewErrDeliveryDoesNotExist() *MyError {
+	return &MyError{Type: "ErrDeliveryDoesNotExist", Inf: Info{Msg: "delivery with this id does not exists", StatusCode: http.StatusNotFound}}
+}
+
+func NewErrDeliveryNotDead() *MyError {
+	return &MyError{Type: "ErrDeliveryNotDead", Inf: Info{Msg: "only dead deliveries can be retried", StatusCode: http.StatusConflict}}
+}
+
+func NewErrInvalidWebhookURL() *MyError {
+	return &MyError{Type: "ErrInvalidWebhookURL", Inf: Info{Msg: "webhook url must be an absolute http or https url of a public host", StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrUnknownEvent(name string) *MyError {
+	return &MyError{Type: "ErrUnknownEvent", Inf: Info{Msg: "unknown event type, use <movie|actor>.<created|updated|deleted>: " + name, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrUnknownDeliveryStatus(status string) *MyError {
+	return &MyError{Type: "ErrUnknownDeliveryStatus", Inf: Info{Msg: "unknown delivery status, use pending, delivered or dead: " + status, StatusCode: http.StatusBadRequest}}
+}
+
+func NewErrRateLimited() *MyError {
+	return &MyError{Type: "ErrRateLimited", Inf: Info{Msg: "too many requests, retry later", StatusCode: http.StatusTooManyRequests}}
+}
+
+func NewErrQuotaExceeded() *MyError {
+	return &MyError{Type: "ErrQuotaExceeded", Inf: Info{Msg: "daily request quota of this API key is used up", StatusCode: http.StatusTooManyRequests}}
+}
+
+func NewErrUnauthorized() *MyError {
+	return &MyError{Type: "ErrUnauthorized", Inf: Info{Msg: "missing, invalid, expired or revoked API key", StatusCode: http.StatusUnauthorized}}
+}
+
+func NewErrForbidden(scope string) *MyError {
+	return &MyError{Type: "ErrForbidden", Inf: Info{Msg: "API key lacks scope: " + scope, StatusCode: http.StatusForbidden}}
+}
+
+func NewErrAPIKeyDoesNotExist() *MyError {
+	return &MyError{Type: "ErrAPIKeyDoesNotExist", Inf: Info{Msg: "API key with this id does not exists", StatusCode: http.StatusNotFound}}
+}
+
+func NewErrUnknownScope(scope string) *MyError {
+	return &MyError{Type: "ErrUnknownScope", Inf: Info{Msg: "unknown scope, use movies:read, movies:write, actors:read, actors:write or admin: " + scope, StatusCode: http.StatusBadRequest}}
 }
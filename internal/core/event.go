@@ -0,0 +1,57 @@
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// Event is a change of a movie or an actor as published on the change feed.
+// Its id is the id of the audit entry it is derived from, so ids increase
+// monotonically. Data is the row after the change, null for deletions.
+type Event struct {
+	Id       int64           `json:"id"`
+	Entity   string          `json:"entity"`
+	EntityId int             `json:"entity_id"`
+	Type     string          `json:"type"`
+	At       time.Time       `json:"at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Name is the event type webhooks subscribe to, such as "movie.created".
+func (event *Event) Name() string {
+	return event.Entity + "." + event.Type
+}
+
+// ValidEventName reports whether name is the Name of some event.
+func ValidEventName(name string) bool {
+	entity, typ, _ := strings.Cut(name, ".")
+	return (entity == AuditMovie || entity == AuditActor) &&
+		(typ == EventCreated || typ == EventUpdated || typ == EventDeleted)
+}
+
+// EventFromAudit returns the event an audit entry publishes, or nil for
+// purges, which follow a deletion that was already published.
+func EventFromAudit(entry *AuditEntry) *Event {
+	event := &Event{Id: entry.Id, Entity: entry.Entity, EntityId: entry.EntityId, At: entry.At, Data: entry.After}
+
+	switch entry.Operation {
+	case AuditCreate, AuditRestore:
+		event.Type = EventCreated
+	case AuditUpdate, AuditLink, AuditUnlink:
+		event.Type = EventUpdated
+	case AuditDelete:
+		event.Type = EventDeleted
+		event.Data = json.RawMessage("null")
+	default:
+		return nil
+	}
+
+	return event
+}
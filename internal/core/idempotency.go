@@ -0,0 +1,9 @@
+package core
+
+// StoredResponse is the response to a request made with an idempotency key,
+// replayed when the request is retried with the same key.
+type StoredResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
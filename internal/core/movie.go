@@ -8,3 +8,17 @@ type Movie struct {
 	Rating  int    `json:"rating" validate:"required"`
 	Actors  []int  `json:"actors" validate:"required"`
 }
+
+// MovieDetail is a movie with its cast expanded into actor objects.
+type MovieDetail struct {
+	Id      int          `json:"id"`
+	Title   string       `json:"title"`
+	Descr   string       `json:"descr"`
+	Release string       `json:"release"`
+	Rating  int          `json:"rating"`
+	Actors  []*CastActor `json:"actors"`
+	// Version is bumped on every change of the movie or its cast links.
+	Version int `json:"-"`
+	// CastVersion changes whenever one of the cast actors changes.
+	CastVersion string `json:"-"`
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	// DeliveryDead marks deliveries that failed too often. They are kept
+	// for inspection and can be retried by hand.
+	DeliveryDead = "dead"
+)
+
+// Webhook subscribes a partner system to events of the change feed.
+type Webhook struct {
+	Id     int      `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs deliveries. It is only returned when the webhook is
+	// created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is an event queued in the outbox for a webhook.
+type WebhookDelivery struct {
+	Id            int64           `json:"id"`
+	WebhookId     int             `json:"webhook_id"`
+	EventId       int64           `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// URL and Secret are those of the webhook, the dispatcher needs them.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
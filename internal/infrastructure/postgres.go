@@ -3,25 +3,112 @@ package infrastructure
 import (
 	"context"
 	"fmt"
-	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"filmoteka/internal/config"
+	"filmoteka/internal/logging"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
 )
 
+// SetUpPostgresDatabase opens the connection pool and waits for Postgres
+// to answer, retrying with backoff as config says, since it often starts
+// after the app does.
 func SetUpPostgresDatabase(ctx context.Context, config *config.DBConfig) (*sqlx.DB, error) {
 
-	db, err := sqlx.ConnectContext(ctx, "pgx", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s",
-		config.Host, config.Port, config.Name, config.Password, config.Db))
+	db, err := sqlx.Open("pgx", postgresDSN(config))
 
 	if err != nil {
-		log.Fatalln(err)
 		return nil, err
 	}
 
-	return db, nil
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+
+	backoff := config.ConnectBackoff
+	for attempt := 1; ; attempt++ {
+		if err = ping(ctx, db, config.ConnectTimeout); err == nil {
+			return db, nil
+		}
+
+		if attempt == config.ConnectAttempts {
+			break
+		}
+
+		logging.From(ctx).WithError(err).WithFields(log.Fields{"attempt": attempt, "backoff": backoff}).Warn("could not connect to postgres, retrying")
+
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, config.ConnectMaxBackoff)
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("could not connect to postgres after %d attempts: %w", config.ConnectAttempts, err)
 
 }
+
+// SetUpPostgresReplicas opens a connection pool per replica, with the pool
+// settings and statement timeout of the primary. Replicas are not waited
+// for, reads go to the primary until their health check passes.
+func SetUpPostgresReplicas(config *config.DBConfig) ([]*sqlx.DB, error) {
+
+	replicas := make([]*sqlx.DB, 0, len(config.Replicas))
+	for i, dsn := range config.Replicas {
+		connConfig, err := pgx.ParseConfig(dsn)
+
+		if err != nil {
+			for _, db := range replicas {
+				db.Close()
+			}
+			return nil, fmt.Errorf("postgres replica %d: %w", i, err)
+		}
+
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10)
+
+		db := sqlx.NewDb(stdlib.OpenDB(*connConfig), "pgx")
+		db.SetMaxOpenConns(config.MaxOpenConns)
+		db.SetMaxIdleConns(config.MaxIdleConns)
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+
+}
+
+func ping(ctx context.Context, db *sqlx.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
+
+// postgresDSN builds the connection string. statement_timeout is passed on
+// to the server as a run-time parameter, in milliseconds. Every value is
+// quoted, passwords read from secret files may hold spaces or quotes.
+func postgresDSN(config *config.DBConfig) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s statement_timeout=%d",
+		dsnValue(config.Host), dsnValue(config.Port), dsnValue(config.Name), dsnValue(config.Password),
+		dsnValue(config.Db), dsnValue(config.SSLMode), config.StatementTimeout.Milliseconds())
+}
+
+// dsnValue quotes value the way libpq reads quoted values of key=value
+// connection strings, with backslashes and single quotes escaped.
+func dsnValue(value string) string {
+	return "'" + dsnEscaper.Replace(value) + "'"
+}
+
+var dsnEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
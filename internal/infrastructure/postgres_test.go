@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"filmoteka/internal/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestPostgresDSN(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		password string
+	}{
+		{"plain", "postgres"},
+		{"empty", ""},
+		{"space", "pass word"},
+		{"quote", "it's"},
+		{"backslash", `back\slash\`},
+		{"injection", "x sslmode=disable host=evil"},
+		{"quoted injection", `' host='evil`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dsn := postgresDSN(&config.DBConfig{
+				Host:             "db.example",
+				Port:             "5434",
+				Name:             "filmoteka",
+				Password:         test.password,
+				Db:               "university",
+				SSLMode:          "require",
+				StatementTimeout: 30 * time.Second,
+			})
+
+			connConfig, err := pgx.ParseConfig(dsn)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", dsn, err)
+			}
+
+			if connConfig.Password != test.password {
+				t.Errorf("password = %q, want %q", connConfig.Password, test.password)
+			}
+			if connConfig.Host != "db.example" || connConfig.Port != 5434 {
+				t.Errorf("host = %s:%d, want db.example:5434", connConfig.Host, connConfig.Port)
+			}
+			if connConfig.TLSConfig == nil {
+				t.Error("sslmode require lost, no TLS config")
+			}
+			if got := connConfig.RuntimeParams["statement_timeout"]; got != "30000" {
+				t.Errorf("statement_timeout = %q, want 30000", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package infrastructure
+
+import (
+	"context"
+
+	"filmoteka/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func SetUpRedis(ctx context.Context, config *config.RedisConfig) (*redis.Client, error) {
+
+	client := redis.NewClient(&redis.Options{Addr: config.Addr, Password: config.Password, DB: config.DB})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+
+}
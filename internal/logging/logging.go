@@ -0,0 +1,51 @@
+// Package logging carries a request-scoped logger in the context, so that
+// every line logged while serving a request can be tied to it.
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// From returns the logger attached to ctx, or the standard logger.
+func From(ctx context.Context) *log.Entry {
+	if entry, ok := ctx.Value(loggerKey{}).(*log.Entry); ok {
+		return entry
+	}
+	return log.NewEntry(log.StandardLogger())
+}
+
+// With attaches entry to ctx.
+func With(ctx context.Context, entry *log.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, entry)
+}
+
+// WithFields attaches the logger of ctx extended with fields to ctx.
+func WithFields(ctx context.Context, fields log.Fields) context.Context {
+	return With(ctx, From(ctx).WithFields(fields))
+}
+
+// Configure sets the level and the format, text or json, of the standard
+// logger.
+func Configure(level, format string) error {
+	parsed, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(parsed)
+
+	switch format {
+	case "text":
+		log.SetFormatter(&log.TextFormatter{})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	return nil
+}
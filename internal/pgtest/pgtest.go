@@ -0,0 +1,226 @@
+// Package pgtest starts a throwaway Postgres cluster for integration tests.
+//
+// The cluster is created with initdb and pg_ctl from PATH (or a standard
+// /usr/lib/postgresql/*/bin directory) in a temporary directory, listens on a
+// unix socket only and has db/migrations applied. Postgres refuses to run as
+// root, so when the tests do, as in CI containers, the cluster runs as the
+// postgres or nobody user. Tests calling Start are skipped when Postgres is
+// not installed.
+package pgtest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+const truncate = "TRUNCATE ActorMovie, Movies, Actors, Audit, IdempotencyKeys, Webhooks, WebhookDeliveries, ApiKeys RESTART IDENTITY CASCADE;"
+
+// Start launches a cluster, applies the migrations and returns a connection
+// to it. The cluster is stopped and removed when the test finishes.
+func Start(t testing.TB) *sqlx.DB {
+	t.Helper()
+
+	bin, err := binDir()
+	if err != nil {
+		t.Skip("pgtest:", err)
+	}
+
+	owner, err := clusterOwner()
+	if err != nil {
+		t.Fatal("pgtest:", err)
+	}
+
+	// Not t.TempDir, whose parent only its creator can enter.
+	dir, err := os.MkdirTemp("", "pgtest")
+	if err != nil {
+		t.Fatal("pgtest:", err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	if owner != nil {
+		if err := os.Chown(dir, int(owner.Uid), int(owner.Gid)); err != nil {
+			t.Fatal("pgtest:", err)
+		}
+	}
+
+	command := func(name string, args ...string) *exec.Cmd {
+		cmd := exec.Command(filepath.Join(bin, name), args...)
+		cmd.Dir = dir
+		if owner != nil {
+			cmd.SysProcAttr = &syscall.SysProcAttr{Credential: owner}
+		}
+		return cmd
+	}
+
+	data := filepath.Join(dir, "data")
+
+	initdb := command("initdb", "-D", data, "-U", "postgres", "-A", "trust", "--no-sync")
+	if out, err := initdb.CombinedOutput(); err != nil {
+		t.Fatalf("pgtest: initdb: %v\n%s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		t.Fatal("pgtest:", err)
+	}
+
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses='' -c fsync=off", port, dir)
+	start := command("pg_ctl", "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-o", options, "-w", "start")
+	if out, err := start.CombinedOutput(); err != nil {
+		t.Fatalf("pgtest: pg_ctl start: %v\n%s", err, out)
+	}
+
+	t.Cleanup(func() {
+		command("pg_ctl", "-D", data, "-m", "immediate", "stop").Run()
+	})
+
+	dsn := fmt.Sprintf("host=%s port=%d user=postgres dbname=postgres sslmode=disable", dir, port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := migrate(ctx, dsn); err != nil {
+		t.Fatal("pgtest:", err)
+	}
+
+	db, err := sqlx.ConnectContext(ctx, "pgx", dsn)
+	if err != nil {
+		t.Fatal("pgtest:", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// Reset removes all rows and restarts id sequences, so every test can start
+// from an empty database without starting a new cluster.
+func Reset(t testing.TB, db *sqlx.DB) {
+	t.Helper()
+
+	if _, err := db.Exec(truncate); err != nil {
+		t.Fatal("pgtest:", err)
+	}
+}
+
+// binDir finds the directory holding initdb and pg_ctl.
+func binDir() (string, error) {
+	if path, err := exec.LookPath("initdb"); err == nil {
+		return filepath.Dir(path), nil
+	}
+
+	dirs, _ := filepath.Glob("/usr/lib/postgresql/*/bin")
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "initdb")); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("initdb not found")
+}
+
+// clusterOwner returns the user the cluster runs as when the tests run as
+// root, and nil otherwise.
+func clusterOwner() (*syscall.Credential, error) {
+	if os.Geteuid() != 0 {
+		return nil, nil
+	}
+
+	for _, name := range []string{"postgres", "nobody"} {
+		account, err := user.Lookup(name)
+		if err != nil {
+			continue
+		}
+
+		uid, err := strconv.ParseUint(account.Uid, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		gid, err := strconv.ParseUint(account.Gid, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+
+		return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+	}
+
+	return nil, fmt.Errorf("running as root and neither the postgres nor the nobody user exists")
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// migrate applies every *.up.sql file of db/migrations in order. The files
+// hold several statements, so they are sent over the simple protocol.
+func migrate(ctx context.Context, dsn string) error {
+	dir, err := migrationsDir()
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	for _, file := range files {
+		migration, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.PgConn().Exec(ctx, string(migration)).ReadAll(); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+	}
+
+	return nil
+}
+
+// migrationsDir walks up from the working directory, which is the package
+// directory under go test, to the module root.
+func migrationsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "db", "migrations"), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("module root not found")
+		}
+		dir = parent
+	}
+}
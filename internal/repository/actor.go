@@ -3,9 +3,10 @@ package repository
 import (
 	"context"
 	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
 	"fmt"
-
-	log "github.com/sirupsen/logrus"
+	"time"
+	"unicode/utf8"
 
 	"errors"
 
@@ -13,133 +14,345 @@ import (
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jmoiron/sqlx"
 )
 
 type ActorRepository struct {
-	Db *sqlx.DB
+	Db       *sqlx.DB
+	Replicas *Replicas
 }
 
 const (
-	CreateActor           = "INSERT INTO Actors(names, sex, bd) SELECT $1, $2, $3 returning id;"
-	DeleteActor           = "DELETE FROM Actors where id = ($1) returning id;"
-	DeleteActorFromMovies = "DELETE FROM ActorMovie where actor_id = $1;"
-	UpdateActor           = "UPDATE Actors SET $1 = $2 where id = $3;"
-	GetAllActors          = `SELECT a.id AS actor_id, a.names AS actor_name, a.sex AS actor_sex, a.names AS actor_name, a.bd AS actor_birthday, 
-	array_agg(m.title) AS movies_participated FROM Actors a LEFT JOIN ActorMovie am ON a.id = am.actor_id LEFT JOIN Movies m ON am.movie_id = m.id GROUP BY a.id, a.names;`
+	CreateActor      = "INSERT INTO Actors(names, sex, bd) SELECT $1, $2, $3 returning id;"
+	UpdateActor      = "UPDATE Actors SET %s = $1, version = version + 1, updated_at = now() where id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3);"
+	LockActor        = "SELECT id FROM Actors WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;"
+	ActorVersion     = "SELECT version FROM Actors WHERE id = $1 AND deleted_at IS NULL;"
+	TouchActorMovies = "UPDATE Movies SET version = version + 1, updated_at = now() WHERE id IN (SELECT movie_id FROM ActorMovie WHERE actor_id = $1);"
+
+	// Deleted actors keep their movie links so that a restore brings them back.
+	DeleteActor     = "UPDATE Actors SET deleted_at = now(), version = version + 1, updated_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2) RETURNING id;"
+	LockAnyActor    = "SELECT deleted_at IS NOT NULL FROM Actors WHERE id = $1 FOR UPDATE;"
+	RestoreActor    = "UPDATE Actors SET deleted_at = NULL, version = version + 1, updated_at = now() WHERE id = $1;"
+	ExpiredActors   = "SELECT id FROM Actors WHERE deleted_at < $1 ORDER BY id FOR UPDATE;"
+	PurgeActorLinks = "DELETE FROM ActorMovie WHERE actor_id = ANY($1);"
+	PurgeActors     = "DELETE FROM Actors WHERE id = ANY($1);"
+
+	// liveMovies joins the links of an actor to movies that are not deleted.
+	liveMovies = "LEFT JOIN (ActorMovie am JOIN Movies m ON m.id = am.movie_id AND m.deleted_at IS NULL) ON am.actor_id = a.id"
+
+	// ActorSnapshot also finds deleted actors, which are restored and purged.
+	ActorSnapshot = `SELECT a.id, a.names, a.sex, a.bd::text, a.version, COALESCE(array_agg(am.movie_id ORDER BY am.movie_id) FILTER (WHERE am.movie_id IS NOT NULL), '{}') AS movies
+	FROM Actors a ` + liveMovies + ` WHERE a.id = $1 GROUP BY a.id;`
+	GetActor = `SELECT a.id, a.names, a.sex, a.bd::text, a.version, COALESCE(array_agg(am.movie_id ORDER BY am.movie_id) FILTER (WHERE am.movie_id IS NOT NULL), '{}') AS movies
+	FROM Actors a ` + liveMovies + ` WHERE a.id = $1 AND a.deleted_at IS NULL GROUP BY a.id;`
+	GetAllActors = `SELECT a.id, a.names, a.sex, a.bd::text, COALESCE(array_agg(am.movie_id) FILTER (WHERE am.movie_id IS NOT NULL), '{}') AS movies
+	FROM Actors a ` + liveMovies + ` WHERE a.deleted_at IS NULL GROUP BY a.id ORDER BY a.id;`
 )
 
-func NewActorRepository(db *sqlx.DB) *ActorRepository {
-	return &ActorRepository{Db: db}
+// actorColumns maps updatable fields of core.Actor to Actors columns.
+var actorColumns = map[string]string{
+	"name": "names",
+	"sex":  "sex",
+	"bd":   "bd",
+}
+
+// NewActorRepository sends the list and search queries to replicas, or to db
+// when replicas is nil.
+func NewActorRepository(db *sqlx.DB, replicas *Replicas) *ActorRepository {
+	return &ActorRepository{Db: db, Replicas: replicas}
 }
 
 func (repository *ActorRepository) CreateActor(ctx context.Context, actor *core.Actor) error {
 
-	_, err := repository.Db.ExecContext(ctx, CreateActor, actor.Name, string(actor.Sex), actor.Bd)
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, CreateActor, actor.Name, string(actor.Sex), actor.Bd).Scan(&actor.Id)
 
 	var e *pgconn.PgError
 	if err != nil {
 		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			log.Info(err.Error())
 			return core.NewErrActorAlreadyExists()
 		}
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditActor, actor.Id, core.AuditCreate, nil, actor); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
 	}
 
 	return nil
 
 }
 
-func (repository *ActorRepository) UpdateActor(ctx context.Context, id int, columnName string, newValue interface{}) error {
+// UpdateActor sets a single column, comparing the stored version with
+// version first unless it is 0.
+func (repository *ActorRepository) UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+
+	column, ok := actorColumns[columnName]
+	if !ok {
+		return core.NewErrUnknownColumn()
+	}
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
 
-	res, err := repository.Db.ExecContext(ctx, UpdateActor, columnName, newValue, id)
+	if err = lockRow(ctx, tx, LockActor, id, core.NewErrActorDoesNotExist()); err != nil {
+		return err
+	}
 
+	before, err := scanActor(ctx, tx.QueryRowContext(ctx, GetActor, id))
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(UpdateActor, column), newValue, id, version)
+
+	var e *pgconn.PgError
+	if err != nil {
+		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+			return core.NewErrActorAlreadyExists()
+		}
+		return dbError(ctx, err)
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	if rows == 0 {
-		log.Info("No rows affected")
-		return core.NewErrActorDoesNotExist()
+		logging.From(ctx).Debug("no rows affected")
+		return versionError(ctx, tx, ActorVersion, id, core.NewErrActorDoesNotExist())
+	}
+
+	after, err := scanActor(ctx, tx.QueryRowContext(ctx, GetActor, id))
+	if err != nil {
+		return err
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditActor, id, core.AuditUpdate, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
 	}
 
 	return nil
 }
 
-func (repository *ActorRepository) DeleteActor(ctx context.Context, id int) error {
+// DeleteActor marks an actor as deleted unless version is set and differs
+// from the stored one. The actor can be restored until it is purged.
+func (repository *ActorRepository) DeleteActor(ctx context.Context, id int, version int) error {
 
-	tx, err := repository.Db.Begin()
+	tx, err := begin(ctx, repository.Db)
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	defer tx.Rollback()
 
-	res, err := tx.ExecContext(ctx, DeleteActor, id)
+	if err = lockRow(ctx, tx, LockActor, id, core.NewErrActorDoesNotExist()); err != nil {
+		return err
+	}
 
+	before, err := scanActor(ctx, tx.QueryRowContext(ctx, GetActor, id))
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, TouchActorMovies, id)
+
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	res, err := tx.ExecContext(ctx, DeleteActor, id, version)
+
+	if err != nil {
+		return dbError(ctx, err)
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	if rows == 0 {
+		return versionError(ctx, tx, ActorVersion, id, core.NewErrActorDoesNotExist())
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditActor, id, core.AuditDelete, before, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+
+}
+
+// RestoreActor brings back a deleted actor together with its movie links.
+// Restoring an actor that is not deleted does nothing.
+func (repository *ActorRepository) RestoreActor(ctx context.Context, id int) error {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	var deleted bool
+	err = tx.QueryRowContext(ctx, LockAnyActor, id).Scan(&deleted)
+	if err == sql.ErrNoRows {
 		return core.NewErrActorDoesNotExist()
 	}
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if !deleted {
+		return nil
+	}
+
+	before, err := scanActor(ctx, tx.QueryRowContext(ctx, ActorSnapshot, id))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, RestoreActor, id)
+
+	var e *pgconn.PgError
+	if err != nil {
+		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+			return core.NewErrActorAlreadyExists()
+		}
+		return dbError(ctx, err)
+	}
 
-	_, err = tx.ExecContext(ctx, DeleteActorFromMovies, id)
+	_, err = tx.ExecContext(ctx, TouchActorMovies, id)
 
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
+	}
+
+	after, err := scanActor(ctx, tx.QueryRowContext(ctx, ActorSnapshot, id))
+	if err != nil {
+		return err
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditActor, id, core.AuditRestore, before, after); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	return nil
+}
 
+// PurgeActors removes actors deleted before the given time for good and
+// returns how many were removed.
+func (repository *ActorRepository) PurgeActors(ctx context.Context, before time.Time) (int, error) {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return 0, dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	ids, err := queryIDs(ctx, tx, ExpiredActors, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err = purge(ctx, tx, core.AuditActor, ids, PurgeActorLinks, PurgeActors); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, dbError(ctx, err)
+	}
+
+	return len(ids), nil
+}
+
+func (repository *ActorRepository) GetActor(ctx context.Context, id int) (*core.Actor, error) {
+	return scanActor(ctx, conn(ctx, repository.Db).QueryRowContext(ctx, GetActor, id))
+}
+
+// scanActor reads the row of the GetActor query.
+func scanActor(ctx context.Context, row *sql.Row) (*core.Actor, error) {
+
+	actor := &core.Actor{}
+	var sex string
+	err := row.Scan(&actor.Id, &actor.Name, &sex, &actor.Bd, &actor.Version, pgtype.NewMap().SQLScanner(&actor.Movies))
+
+	if err == sql.ErrNoRows {
+		return nil, core.NewErrActorDoesNotExist()
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	actor.Sex, _ = utf8.DecodeRuneInString(sex)
+
+	return actor, nil
 }
 
 func (repository *ActorRepository) GetAllActors(ctx context.Context) ([]*core.Actor, error) {
 	var actors []*core.Actor
 
-	rows, err := repository.Db.QueryContext(ctx, GetAllActors)
+	rows, err := conn(ctx, reader(ctx, repository.Db, repository.Replicas)).QueryContext(ctx, GetAllActors)
 	if err == sql.ErrNoRows {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 	if err != nil {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 
+	defer rows.Close()
+
+	typeMap := pgtype.NewMap()
+
 	for rows.Next() {
 		actor := &core.Actor{}
-		err = rows.Scan(&actor.Id, &actor.Name, &actor.Sex, &actor.Bd, &actor.Movies)
+		var sex string
+		err = rows.Scan(&actor.Id, &actor.Name, &sex, &actor.Bd, typeMap.SQLScanner(&actor.Movies))
 
 		if err != nil {
-			log.Info(err.Error())
-			return nil, fmt.Errorf("Internal server error")
+			return nil, dbError(ctx, err)
 		}
+		actor.Sex, _ = utf8.DecodeRuneInString(sex)
 		actors = append(actors, actor)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
 	return actors, nil
 }
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/pgtest"
+)
+
+func TestActorRepository(t *testing.T) {
+
+	db := pgtest.Start(t)
+	movies := NewMovieRepository(db, nil)
+	actors := NewActorRepository(db, nil)
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		pgtest.Reset(t, db)
+
+		actor := &core.Actor{Name: "Una Stubbs", Sex: 'F', Bd: "1937-05-01"}
+		if err := actors.CreateActor(ctx, actor); err != nil {
+			t.Fatal(err)
+		}
+		if actor.Id != 1 {
+			t.Errorf("id = %d, want 1", actor.Id)
+		}
+
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor.Id)
+
+		stored, err := actors.GetActor(ctx, actor.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Name != "Una Stubbs" || stored.Sex != 'F' || stored.Bd != "1937-05-01" || !reflect.DeepEqual(stored.Movies, []int{movie}) {
+			t.Errorf("actor = %+v", stored)
+		}
+
+		assertError(t, actors.CreateActor(ctx, &core.Actor{Name: "Una Stubbs", Sex: 'F', Bd: "1937-05-01"}), core.NewErrActorAlreadyExists())
+
+		_, err = actors.GetActor(ctx, 42)
+		assertError(t, err, core.NewErrActorDoesNotExist())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		id := createActor(t, actors, "Benedict Cumberbatch")
+		createActor(t, actors, "Martin Freeman")
+
+		for column, value := range map[string]interface{}{"name": "Benedict Timothy Carlton Cumberbatch", "sex": "F", "bd": "1976-07-20"} {
+			if err := actors.UpdateActor(ctx, id, 0, column, value); err != nil {
+				t.Fatalf("updating %s: %v", column, err)
+			}
+		}
+
+		stored, err := actors.GetActor(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.Name != "Benedict Timothy Carlton Cumberbatch" || stored.Sex != 'F' || stored.Bd != "1976-07-20" {
+			t.Errorf("actor = %+v", stored)
+		}
+
+		if err := actors.UpdateActor(ctx, id, stored.Version, "sex", "M"); err != nil {
+			t.Fatalf("updating at the current version: %v", err)
+		}
+
+		tests := []struct {
+			name    string
+			id      int
+			version int
+			column  string
+			value   interface{}
+			want    *core.MyError
+		}{
+			{"stale version", id, stored.Version, "sex", "F", core.NewErrVersionMismatch()},
+			{"duplicate name", id, 0, "name", "Martin Freeman", core.NewErrActorAlreadyExists()},
+			{"unknown column", id, 0, "movies", "1", core.NewErrUnknownColumn()},
+			{"missing actor", 42, 0, "sex", "F", core.NewErrActorDoesNotExist()},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				assertError(t, actors.UpdateActor(ctx, test.id, test.version, test.column, test.value), test.want)
+			})
+		}
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		id := createActor(t, actors, "Benedict Cumberbatch")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", id)
+
+		stored, err := actors.GetActor(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertError(t, actors.DeleteActor(ctx, id, stored.Version+1), core.NewErrVersionMismatch())
+		if err := actors.DeleteActor(ctx, id, stored.Version); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = actors.GetActor(ctx, id)
+		assertError(t, err, core.NewErrActorDoesNotExist())
+		assertError(t, actors.DeleteActor(ctx, id, 0), core.NewErrActorDoesNotExist())
+
+		detail, err := movies.GetMovie(ctx, movie)
+		if err != nil {
+			t.Fatalf("movie of the deleted actor: %v", err)
+		}
+		if len(detail.Actors) != 0 {
+			t.Errorf("cast = %v, want the deleted actor left out", detailActorIDs(detail))
+		}
+
+		// The name is free while the actor is deleted.
+		other := createActor(t, actors, "Benedict Cumberbatch")
+		assertError(t, actors.RestoreActor(ctx, id), core.NewErrActorAlreadyExists())
+
+		if err := actors.DeleteActor(ctx, other, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := actors.RestoreActor(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+		if err := actors.RestoreActor(ctx, id); err != nil {
+			t.Fatalf("restoring a live actor: %v", err)
+		}
+
+		stored, err = actors.GetActor(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(stored.Movies, []int{movie}) {
+			t.Errorf("actor movies = %v, want them restored with the actor", stored.Movies)
+		}
+
+		assertError(t, actors.RestoreActor(ctx, 42), core.NewErrActorDoesNotExist())
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		id := createActor(t, actors, "Benedict Cumberbatch")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", id)
+
+		if err := actors.DeleteActor(ctx, id, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		purged, err := actors.PurgeActors(ctx, time.Now().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Fatalf("purging before the deletion = %d, %v, want 0", purged, err)
+		}
+
+		purged, err = actors.PurgeActors(ctx, time.Now().Add(time.Hour))
+		if err != nil || purged != 1 {
+			t.Fatalf("purged = %d, %v, want 1", purged, err)
+		}
+
+		assertError(t, actors.RestoreActor(ctx, id), core.NewErrActorDoesNotExist())
+
+		if _, err := movies.GetMovie(ctx, movie); err != nil {
+			t.Fatalf("movie of the purged actor: %v", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		first := createActor(t, actors, "Benedict Cumberbatch")
+		second := createActor(t, actors, "Martin Freeman")
+		deleted := createActor(t, actors, "Andrew Scott")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", first)
+
+		if err := actors.DeleteActor(ctx, deleted, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		list, err := actors.GetAllActors(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 || list[0].Id != first || list[1].Id != second {
+			t.Fatalf("actors = %+v, want %d and %d", list, first, second)
+		}
+		if !reflect.DeepEqual(list[0].Movies, []int{movie}) || len(list[1].Movies) != 0 {
+			t.Errorf("movies = %v and %v, want [%d] and none", list[0].Movies, list[1].Movies, movie)
+		}
+	})
+}
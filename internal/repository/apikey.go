@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"filmoteka/internal/core"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepository struct {
+	Db *sqlx.DB
+}
+
+const (
+	CreateAPIKey = "INSERT INTO ApiKeys(name, prefix, key_hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at;"
+	ListAPIKeys  = "SELECT id, name, prefix, scopes, expires_at, created_at, last_used_at FROM ApiKeys WHERE revoked_at IS NULL ORDER BY id;"
+	RevokeAPIKey = "UPDATE ApiKeys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL;"
+	FindAPIKey   = "SELECT id, name, prefix, scopes, expires_at, created_at, last_used_at FROM ApiKeys WHERE key_hash = $1 AND revoked_at IS NULL;"
+	TouchAPIKey  = "UPDATE ApiKeys SET last_used_at = $2 WHERE id = $1;"
+)
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{Db: db}
+}
+
+func (repository *APIKeyRepository) CreateAPIKey(ctx context.Context, key *core.APIKey, hash string) error {
+
+	err := conn(ctx, repository.Db).QueryRowContext(ctx, CreateAPIKey, key.Name, key.Prefix, hash, key.Scopes, key.ExpiresAt).Scan(&key.Id, &key.CreatedAt)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+// ListAPIKeys returns every key that was not revoked.
+func (repository *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*core.APIKey, error) {
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, ListAPIKeys)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	typeMap := pgtype.NewMap()
+	keys := []*core.APIKey{}
+	for rows.Next() {
+		key := &core.APIKey{}
+		if err := rows.Scan(&key.Id, &key.Name, &key.Prefix, typeMap.SQLScanner(&key.Scopes), &key.ExpiresAt, &key.CreatedAt, &key.LastUsedAt); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked. The row is kept so that the hash can
+// never be issued again.
+func (repository *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int) error {
+
+	res, err := conn(ctx, repository.Db).ExecContext(ctx, RevokeAPIKey, id)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return core.NewErrAPIKeyDoesNotExist()
+	}
+
+	return nil
+}
+
+// FindAPIKey returns the key that was not revoked with the given hash, or
+// nil.
+func (repository *APIKeyRepository) FindAPIKey(ctx context.Context, hash string) (*core.APIKey, error) {
+
+	key := &core.APIKey{}
+	err := conn(ctx, repository.Db).QueryRowContext(ctx, FindAPIKey, hash).Scan(&key.Id, &key.Name, &key.Prefix, pgtype.NewMap().SQLScanner(&key.Scopes), &key.ExpiresAt, &key.CreatedAt, &key.LastUsedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return key, nil
+}
+
+func (repository *APIKeyRepository) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+
+	if _, err := conn(ctx, repository.Db).ExecContext(ctx, TouchAPIKey, id, at); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"filmoteka/internal/core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditRepository struct {
+	Db *sqlx.DB
+}
+
+const (
+	// LockAudit serializes writers of the audit log until they commit, so
+	// entries become visible in id order and readers paging by id, such as
+	// the event feed, never skip one. The cost is that every write to the
+	// catalog waits for the one before it to commit, and a batch holding
+	// row locks when it takes the lock can deadlock with another writer;
+	// Transactor retries the transaction Postgres aborts to break the cycle.
+	LockAudit   = "SELECT pg_advisory_xact_lock(hashtext('Audit'));"
+	InsertAudit = "INSERT INTO Audit(user_name, entity, entity_id, operation, before, after) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at;"
+	ListAudit   = `SELECT id, user_name, created_at, entity, entity_id, operation, before, after FROM Audit
+	WHERE ($1 = '' OR entity = $1) AND ($2 = 0 OR entity_id = $2) AND id > $3 ORDER BY id LIMIT $4;`
+)
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{Db: db}
+}
+
+// ListAudit returns up to filter.Limit entries in the order they were written.
+func (repository *AuditRepository) ListAudit(ctx context.Context, filter core.AuditFilter) ([]*core.AuditEntry, error) {
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, ListAudit, filter.Entity, filter.EntityId, filter.After, filter.Limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	var entries []*core.AuditEntry
+	for rows.Next() {
+		entry := &core.AuditEntry{}
+		var before, after []byte
+		err := rows.Scan(&entry.Id, &entry.User, &entry.At, &entry.Entity, &entry.EntityId, &entry.Operation, &before, &after)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		entry.Before, entry.After = before, after
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return entries, nil
+}
+
+// writeAudit appends an entry to the audit log within the transaction of the
+// change it records and queues the event it publishes for webhooks. before
+// and after are rendered as JSON, nil as null.
+func writeAudit(ctx context.Context, tx queryer, entity string, id int, operation string, before, after interface{}) error {
+
+	beforeJSON, err := core.AuditSnapshot(before)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	afterJSON, err := core.AuditSnapshot(after)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, LockAudit); err != nil {
+		return dbError(ctx, err)
+	}
+
+	entry := &core.AuditEntry{Entity: entity, EntityId: id, Operation: operation, Before: beforeJSON, After: afterJSON}
+	err = tx.QueryRowContext(ctx, InsertAudit, core.UserFrom(ctx), entity, id, operation, jsonArg(beforeJSON), jsonArg(afterJSON)).Scan(&entry.Id, &entry.At)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return queueDeliveries(ctx, tx, entry)
+}
+
+// jsonArg binds a JSON document to a jsonb parameter, nil as NULL.
+func jsonArg(doc []byte) interface{} {
+	if doc == nil {
+		return nil
+	}
+	return string(doc)
+}
+
+// purge deletes the links and then the rows with the given ids, recording
+// each removal in the audit log.
+func purge(ctx context.Context, tx queryer, entity string, ids []int, deleteLinks, deleteRows string) error {
+
+	if _, err := tx.ExecContext(ctx, deleteLinks, ids); err != nil {
+		return dbError(ctx, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteRows, ids); err != nil {
+		return dbError(ctx, err)
+	}
+
+	for _, id := range ids {
+		if err := writeAudit(ctx, tx, entity, id, core.AuditPurge, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+// Package cache serves the movie list and search queries from a cache in
+// front of a MovieRepository. Cached entries are keyed by a generation that
+// Invalidate bumps, so a result read before a write can never be served
+// after the write was invalidated.
+package cache
+
+import (
+	"context"
+	"expvar"
+)
+
+// Backend stores cached query results for the TTL it was created with.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+	// Generation returns a counter that Invalidate increments.
+	Generation(ctx context.Context) (int64, error)
+	Invalidate(ctx context.Context) error
+}
+
+// metrics is published at /debug/vars: hits and misses of the cache,
+// misses served by a load another request started, and backend errors.
+var metrics = expvar.NewMap("cache")
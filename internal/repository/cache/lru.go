@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Backend that keeps up to size entries and drops the
+// least recently used one first.
+type LRU struct {
+	size int
+	ttl  time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	order      *list.List
+	generation int64
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{size: size, ttl: ttl, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+func (lru *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	element, ok := lru.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		lru.remove(element)
+		return nil, false, nil
+	}
+
+	lru.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (lru *LRU) Set(ctx context.Context, key string, value []byte) error {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	if element, ok := lru.entries[key]; ok {
+		lru.remove(element)
+	}
+
+	lru.entries[key] = lru.order.PushFront(&lruEntry{key: key, value: value, expires: time.Now().Add(lru.ttl)})
+	for lru.order.Len() > lru.size {
+		lru.remove(lru.order.Back())
+	}
+
+	return nil
+}
+
+func (lru *LRU) Generation(ctx context.Context) (int64, error) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	return lru.generation, nil
+}
+
+// Invalidate also drops every entry, they could not be read anymore.
+func (lru *LRU) Invalidate(ctx context.Context) error {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	lru.generation++
+	lru.entries = map[string]*list.Element{}
+	lru.order.Init()
+
+	return nil
+}
+
+// remove drops an entry. Callers must hold the lock.
+func (lru *LRU) remove(element *list.Element) {
+	lru.order.Remove(element)
+	delete(lru.entries, element.Value.(*lruEntry).key)
+}
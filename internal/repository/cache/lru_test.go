@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		lru := NewLRU(2, time.Minute)
+		lru.Set(ctx, "a", []byte("1"))
+		lru.Set(ctx, "b", []byte("2"))
+
+		// Reading a makes b the least recently used entry.
+		if _, ok, _ := lru.Get(ctx, "a"); !ok {
+			t.Fatal("a missing")
+		}
+		lru.Set(ctx, "c", []byte("3"))
+
+		for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+			if _, ok, _ := lru.Get(ctx, key); ok != want {
+				t.Errorf("%s cached = %v, want %v", key, ok, want)
+			}
+		}
+	})
+
+	t.Run("replaces an entry", func(t *testing.T) {
+		lru := NewLRU(2, time.Minute)
+		lru.Set(ctx, "a", []byte("1"))
+		lru.Set(ctx, "a", []byte("2"))
+
+		if value, _, _ := lru.Get(ctx, "a"); string(value) != "2" {
+			t.Errorf("a = %s, want 2", value)
+		}
+		if lru.order.Len() != 1 {
+			t.Errorf("%d entries, want 1", lru.order.Len())
+		}
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		lru := NewLRU(2, time.Nanosecond)
+		lru.Set(ctx, "a", []byte("1"))
+		time.Sleep(time.Millisecond)
+
+		if _, ok, _ := lru.Get(ctx, "a"); ok {
+			t.Error("expired entry served")
+		}
+		if len(lru.entries) != 0 {
+			t.Errorf("%d entries, want the expired one dropped", len(lru.entries))
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		lru := NewLRU(2, time.Minute)
+		lru.Set(ctx, "a", []byte("1"))
+
+		before, _ := lru.Generation(ctx)
+		if err := lru.Invalidate(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if after, _ := lru.Generation(ctx); after != before+1 {
+			t.Errorf("generation = %d, want %d", after, before+1)
+		}
+		if _, ok, _ := lru.Get(ctx, "a"); ok {
+			t.Error("entry served after invalidation")
+		}
+	})
+}
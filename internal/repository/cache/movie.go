@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+type MovieRepository interface {
+	CreateMovie(ctx context.Context, movie *core.Movie) error
+	DeleteMovie(ctx context.Context, id int, version int) error
+	RestoreMovie(ctx context.Context, id int) error
+	PurgeMovies(ctx context.Context, before time.Time) (int, error)
+	UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	AddActors(ctx context.Context, id int, cast []core.CastMember) error
+	DeleteActors(ctx context.Context, id int, cast []core.CastMember) error
+	GetMovie(ctx context.Context, id int) (*core.MovieDetail, error)
+	GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error)
+	GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error)
+	GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error)
+	SearchMovie(ctx context.Context, search string) ([]*core.Movie, error)
+}
+
+// CachedMovieRepository serves the list and search queries of the wrapped
+// repository from backend and passes everything else through. Concurrent
+// misses of the same query share one load. The service layer must call
+// Invalidate after every committed change of movies or actors, and list
+// queries must not run within a unit of work, whose uncommitted results
+// would be cached.
+type CachedMovieRepository struct {
+	MovieRepository
+	backend Backend
+	loads   singleflight.Group
+}
+
+func NewCachedMovieRepository(repository MovieRepository, backend Backend) *CachedMovieRepository {
+	return &CachedMovieRepository{MovieRepository: repository, backend: backend}
+}
+
+func (repository *CachedMovieRepository) GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error) {
+	return repository.cached(ctx, "movies?sort=rating", repository.MovieRepository.GetAllMoviesByRating)
+}
+
+func (repository *CachedMovieRepository) GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error) {
+	return repository.cached(ctx, "movies?sort=title", repository.MovieRepository.GetAllMoviesByTitle)
+}
+
+func (repository *CachedMovieRepository) GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error) {
+	return repository.cached(ctx, "movies?sort=release", repository.MovieRepository.GetAllMoviesByReleaseDate)
+}
+
+func (repository *CachedMovieRepository) SearchMovie(ctx context.Context, search string) ([]*core.Movie, error) {
+	return repository.cached(ctx, "movies/search?q="+search, func(ctx context.Context) ([]*core.Movie, error) {
+		return repository.MovieRepository.SearchMovie(ctx, search)
+	})
+}
+
+// Invalidate makes every cached result stale.
+func (repository *CachedMovieRepository) Invalidate(ctx context.Context) error {
+	if err := repository.backend.Invalidate(ctx); err != nil {
+		metrics.Add("errors", 1)
+		return err
+	}
+	return nil
+}
+
+// cached returns the result of query from the backend, or loads and stores
+// it. Backend failures fall back to the wrapped repository.
+func (repository *CachedMovieRepository) cached(ctx context.Context, query string, load func(ctx context.Context) ([]*core.Movie, error)) ([]*core.Movie, error) {
+	generation, err := repository.backend.Generation(ctx)
+	if err != nil {
+		logging.From(ctx).WithError(err).Warn("cache unavailable")
+		metrics.Add("errors", 1)
+		return load(ctx)
+	}
+
+	key := strconv.FormatInt(generation, 10) + ":" + query
+
+	data, ok, err := repository.backend.Get(ctx, key)
+	if err != nil {
+		logging.From(ctx).WithError(err).Warn("cache read failed")
+		metrics.Add("errors", 1)
+	}
+
+	if ok {
+		metrics.Add("hits", 1)
+	} else {
+		metrics.Add("misses", 1)
+
+		// The load is shared, so it must not end when the request that
+		// started it is cancelled, and must see every committed write.
+		loaded, err, shared := repository.loads.Do(key, func() (interface{}, error) {
+			ctx := context.WithoutCancel(ctx)
+			movies, err := load(core.WithSharedRead(ctx))
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := json.Marshal(movies)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := repository.backend.Set(ctx, key, data); err != nil {
+				logging.From(ctx).WithError(err).Warn("cache write failed")
+				metrics.Add("errors", 1)
+			}
+			return data, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if shared {
+			metrics.Add("shared", 1)
+		}
+		data = loaded.([]byte)
+	}
+
+	// Every caller decodes its own copy, callers may change the movies.
+	var movies []*core.Movie
+	if err := json.Unmarshal(data, &movies); err != nil {
+		logging.From(ctx).WithError(err).Error("cached movies are corrupt")
+		return nil, core.NewErrInternal()
+	}
+	return movies, nil
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+)
+
+// countingRepository counts the list queries that reach it. While block is
+// set, loads wait for it to be closed after signalling started.
+type countingRepository struct {
+	MovieRepository
+	loads   atomic.Int32
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (repository *countingRepository) GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error) {
+	if repository.loads.Add(1) == 1 && repository.block != nil {
+		close(repository.started)
+		<-repository.block
+	}
+	return []*core.Movie{{Id: 1, Title: "Sherlock", Actors: []int{1}}}, nil
+}
+
+// mapBackend keeps entries until the test ends, like Redis within the ttl,
+// so only the generation keeps stale results from being served.
+type mapBackend struct {
+	mu         sync.Mutex
+	entries    map[string][]byte
+	generation int64
+	err        error
+}
+
+func (backend *mapBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	value, ok := backend.entries[key]
+	return value, ok, backend.err
+}
+
+func (backend *mapBackend) Set(ctx context.Context, key string, value []byte) error {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	backend.entries[key] = value
+	return backend.err
+}
+
+func (backend *mapBackend) Generation(ctx context.Context) (int64, error) {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	return backend.generation, backend.err
+}
+
+func (backend *mapBackend) Invalidate(ctx context.Context) error {
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	backend.generation++
+	return backend.err
+}
+
+func listMovies(t *testing.T, repository *CachedMovieRepository) []*core.Movie {
+	t.Helper()
+
+	movies, err := repository.GetAllMoviesByTitle(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movies) != 1 || movies[0].Title != "Sherlock" {
+		t.Fatalf("movies = %+v, want Sherlock", movies)
+	}
+	return movies
+}
+
+func TestCachedMovieRepository(t *testing.T) {
+
+	t.Run("invalidate bumps the generation", func(t *testing.T) {
+		movies := &countingRepository{}
+		backend := &mapBackend{entries: map[string][]byte{}}
+		cached := NewCachedMovieRepository(movies, backend)
+
+		listMovies(t, cached)
+		listMovies(t, cached)
+		if got := movies.loads.Load(); got != 1 {
+			t.Fatalf("%d loads, want the second list served from the cache", got)
+		}
+
+		if err := cached.Invalidate(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		listMovies(t, cached)
+		if got := movies.loads.Load(); got != 2 {
+			t.Errorf("%d loads, want the list loaded again after invalidation", got)
+		}
+		if _, ok := backend.entries["0:movies?sort=title"]; !ok {
+			t.Errorf("entries = %v, want the result of generation 0 kept", backend.entries)
+		}
+	})
+
+	t.Run("callers get their own copy", func(t *testing.T) {
+		cached := NewCachedMovieRepository(&countingRepository{}, NewLRU(10, time.Minute))
+
+		listMovies(t, cached)[0].Actors[0] = 42
+		if got := listMovies(t, cached)[0].Actors; got[0] != 1 {
+			t.Errorf("actors = %v, want the cached result unchanged", got)
+		}
+	})
+
+	t.Run("concurrent misses share one load", func(t *testing.T) {
+		movies := &countingRepository{started: make(chan struct{}), block: make(chan struct{})}
+		cached := NewCachedMovieRepository(movies, NewLRU(10, time.Minute))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				listMovies(t, cached)
+			}()
+		}
+
+		// Give the other callers time to join the load before it ends.
+		<-movies.started
+		time.Sleep(20 * time.Millisecond)
+		close(movies.block)
+		wg.Wait()
+
+		if got := movies.loads.Load(); got != 1 {
+			t.Errorf("%d loads, want 1", got)
+		}
+	})
+
+	t.Run("backend errors fall back to the repository", func(t *testing.T) {
+		movies := &countingRepository{}
+		backend := &mapBackend{entries: map[string][]byte{}, err: errors.New("connection refused")}
+		cached := NewCachedMovieRepository(movies, backend)
+
+		listMovies(t, cached)
+		listMovies(t, cached)
+		if got := movies.loads.Load(); got != 2 {
+			t.Errorf("%d loads, want every list loaded", got)
+		}
+		if err := cached.Invalidate(context.Background()); err == nil {
+			t.Error("Invalidate hid the backend error")
+		}
+	})
+}
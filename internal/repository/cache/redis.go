@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Backend shared by every instance using the same Redis server
+// and prefix, so a write through one instance invalidates all of them.
+type Redis struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedis(client *redis.Client, prefix string, ttl time.Duration) *Redis {
+	return &Redis{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (backend *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := backend.client.Get(ctx, backend.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (backend *Redis) Set(ctx context.Context, key string, value []byte) error {
+	return backend.client.Set(ctx, backend.prefix+key, value, backend.ttl).Err()
+}
+
+func (backend *Redis) Generation(ctx context.Context) (int64, error) {
+	generation, err := backend.client.Get(ctx, backend.prefix+"generation").Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Invalidate leaves old entries to expire by themselves.
+func (backend *Redis) Invalidate(ctx context.Context) error {
+	return backend.client.Incr(ctx, backend.prefix+"generation").Err()
+}
@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"filmoteka/internal/core"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// violationKey extracts the first column and value from the detail of a
+// constraint violation, e.g. `Key (actor_id)=(5) is not present in table "actors".`
+// or `Key (actor_id, movie_id)=(5, 1) already exists.`
+var violationKey = regexp.MustCompile(`Key \((\w+)(?:, \w+)*\)=\((\d+)`)
+
+// AddActors links actors to a movie. Nothing is linked unless the movie and
+// every actor exist and none of the actors is linked to the movie already.
+func (repository *MovieRepository) AddActors(ctx context.Context, id int, cast []core.CastMember) error {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	cast, err = resolveCast(ctx, tx, id, cast)
+	if err != nil {
+		return err
+	}
+
+	actorIDs := castIDs(cast)
+	linked, err := queryIDs(ctx, tx, LinkedActors, actorIDs, id)
+	if err != nil {
+		return err
+	}
+
+	if len(linked) > 0 {
+		return core.NewErrActorsAlreadyLinked(linked)
+	}
+
+	characters := make([]string, 0, len(cast))
+	billing := make([]int, 0, len(cast))
+	for _, member := range cast {
+		characters = append(characters, member.Character)
+		billing = append(billing, member.Billing)
+	}
+
+	before, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, AddActorsToMovie, actorIDs, characters, billing, id)
+
+	if err != nil {
+		return linkError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, TouchActors, actorIDs)
+
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	after, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, id, core.AuditLink, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+// DeleteActors unlinks actors from a movie. Nothing is unlinked unless the
+// movie and every actor exist and all the actors are linked to the movie.
+func (repository *MovieRepository) DeleteActors(ctx context.Context, id int, cast []core.CastMember) error {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	cast, err = resolveCast(ctx, tx, id, cast)
+	if err != nil {
+		return err
+	}
+
+	actorIDs := castIDs(cast)
+	linked, err := queryIDs(ctx, tx, LinkedActors, actorIDs, id)
+	if err != nil {
+		return err
+	}
+
+	if notLinked := subtractIDs(actorIDs, linked); len(notLinked) > 0 {
+		return core.NewErrActorsNotLinked(notLinked)
+	}
+
+	before, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, DeleteActorsFromMovie, actorIDs, id)
+
+	if err != nil {
+		return linkError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, TouchActors, actorIDs)
+
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	after, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, id, core.AuditUnlink, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+// resolveCast locks the movie row and bumps its version, resolves actor
+// names to ids and makes sure every actor exists, listing all the missing
+// ones. The returned cast has an id set on every member and holds each
+// actor once. An empty cast is refused before the movie is touched.
+func resolveCast(ctx context.Context, tx queryer, id int, cast []core.CastMember) ([]core.CastMember, error) {
+
+	if len(cast) == 0 {
+		return nil, core.NewErrBadRequest()
+	}
+
+	for _, member := range cast {
+		if !member.Valid() {
+			return nil, core.NewErrBadRequest()
+		}
+	}
+
+	var movieID int
+	err := tx.QueryRowContext(ctx, TouchMovie, id).Scan(&movieID)
+	if err == sql.ErrNoRows {
+		return nil, core.NewErrMovieDoesNotExist()
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	var names []string
+	for _, member := range cast {
+		if member.ActorId == 0 {
+			names = append(names, member.ActorName)
+		}
+	}
+
+	byName := map[string]int{}
+	if len(names) > 0 {
+		if byName, err = queryNames(ctx, tx, names); err != nil {
+			return nil, err
+		}
+	}
+
+	var unknown []string
+	resolved := make([]core.CastMember, 0, len(cast))
+	for _, member := range cast {
+		if member.ActorId == 0 {
+			actorID, ok := byName[member.ActorName]
+			if !ok {
+				unknown = append(unknown, member.ActorName)
+				continue
+			}
+			member.ActorId = actorID
+		}
+		resolved = append(resolved, member)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, core.NewErrActorNamesDoNotExist(unknown)
+	}
+
+	resolved = uniqueCast(resolved)
+
+	missing, err := queryIDs(ctx, tx, MissingActors, castIDs(resolved))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		return nil, core.NewErrActorsDoNotExist(missing)
+	}
+
+	return resolved, nil
+}
+
+func queryNames(ctx context.Context, tx queryer, names []string) (map[string]int, error) {
+
+	rows, err := tx.QueryContext(ctx, ActorsByName, names)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	ids := map[string]int{}
+	for rows.Next() {
+		var name string
+		var id int
+		if err := rows.Scan(&name, &id); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		ids[name] = id
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return ids, nil
+}
+
+func queryIDs(ctx context.Context, tx queryer, query string, args ...interface{}) ([]int, error) {
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return ids, nil
+}
+
+// uniqueCast keeps the first mention of every actor.
+func uniqueCast(cast []core.CastMember) []core.CastMember {
+	seen := make(map[int]struct{}, len(cast))
+	unique := make([]core.CastMember, 0, len(cast))
+	for _, member := range cast {
+		if _, ok := seen[member.ActorId]; !ok {
+			seen[member.ActorId] = struct{}{}
+			unique = append(unique, member)
+		}
+	}
+	return unique
+}
+
+func castIDs(cast []core.CastMember) []int {
+	ids := make([]int, 0, len(cast))
+	for _, member := range cast {
+		ids = append(ids, member.ActorId)
+	}
+	return ids
+}
+
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]struct{}, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// subtractIDs returns the ids of a that are not in b.
+func subtractIDs(a, b []int) []int {
+	exclude := make(map[int]struct{}, len(b))
+	for _, id := range b {
+		exclude[id] = struct{}{}
+	}
+
+	var rest []int
+	for _, id := range a {
+		if _, ok := exclude[id]; !ok {
+			rest = append(rest, id)
+		}
+	}
+	return rest
+}
+
+// linkError turns a constraint violation on ActorMovie, which the checks
+// above can only miss under a race, into an error naming the offending id.
+func linkError(ctx context.Context, err error) error {
+	var e *pgconn.PgError
+	if !errors.As(err, &e) {
+		return dbError(ctx, err)
+	}
+
+	key := violationKey.FindStringSubmatch(e.Detail)
+	if key == nil {
+		return dbError(ctx, err)
+	}
+
+	id, _ := strconv.Atoi(key[2])
+
+	switch {
+	case e.Code == pgerrcode.UniqueViolation:
+		return core.NewErrActorsAlreadyLinked([]int{id})
+	case e.Code == pgerrcode.ForeignKeyViolation && key[1] == "actor_id":
+		return core.NewErrActorsDoNotExist([]int{id})
+	case e.Code == pgerrcode.ForeignKeyViolation && key[1] == "movie_id":
+		return core.NewErrMovieDoesNotExist()
+	}
+
+	return dbError(ctx, err)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/pgtest"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestCast(t *testing.T) {
+
+	db := pgtest.Start(t)
+	movies := NewMovieRepository(db, nil)
+	actors := NewActorRepository(db, nil)
+	ctx := context.Background()
+
+	t.Run("add and delete", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		benedict := createActor(t, actors, "Benedict Cumberbatch")
+		martin := createActor(t, actors, "Martin Freeman")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+		other := createMovie(t, movies, "Doctor Strange", 8, "2016-10-20", benedict)
+
+		cast := []core.CastMember{
+			{ActorId: martin, Character: "John Watson", Billing: 2},
+			{ActorName: "Benedict Cumberbatch", Character: "Sherlock Holmes", Billing: 1},
+			{ActorId: martin},
+		}
+		if err := movies.AddActors(ctx, movie, cast); err != nil {
+			t.Fatal(err)
+		}
+
+		detail, err := movies.GetMovie(ctx, movie)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{benedict, martin}) {
+			t.Fatalf("cast = %v, want it in billing order", got)
+		}
+		if detail.Actors[0].Character != "Sherlock Holmes" || detail.Actors[0].Billing != 1 || detail.Actors[1].Character != "John Watson" {
+			t.Errorf("cast = %+v, %+v", detail.Actors[0], detail.Actors[1])
+		}
+
+		if err := movies.DeleteActors(ctx, movie, []core.CastMember{{ActorName: "Martin Freeman"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		detail, err = movies.GetMovie(ctx, movie)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{benedict}) {
+			t.Errorf("cast = %v, want %v", got, []int{benedict})
+		}
+
+		// delete_actors_from_movie used to unlink the actors from every movie.
+		if err := movies.DeleteActors(ctx, movie, []core.CastMember{{ActorId: benedict}}); err != nil {
+			t.Fatal(err)
+		}
+		detail, err = movies.GetMovie(ctx, other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{benedict}) {
+			t.Errorf("cast of another movie = %v, want it unchanged", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		benedict := createActor(t, actors, "Benedict Cumberbatch")
+		martin := createActor(t, actors, "Martin Freeman")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", benedict)
+
+		tests := []struct {
+			name   string
+			change func(context.Context, int, []core.CastMember) error
+			movie  int
+			cast   []core.CastMember
+			want   *core.MyError
+		}{
+			{"add linked actors", movies.AddActors, movie, []core.CastMember{{ActorId: martin}, {ActorId: benedict}}, core.NewErrActorsAlreadyLinked([]int{benedict})},
+			{"add missing actors", movies.AddActors, movie, []core.CastMember{{ActorId: 9}, {ActorId: martin}, {ActorId: 7}}, core.NewErrActorsDoNotExist([]int{7, 9})},
+			{"add unknown names", movies.AddActors, movie, []core.CastMember{{ActorName: "Nobody"}, {ActorName: "Andrew Scott"}}, core.NewErrActorNamesDoNotExist([]string{"Andrew Scott", "Nobody"})},
+			{"add to a missing movie", movies.AddActors, 42, []core.CastMember{{ActorId: martin}}, core.NewErrMovieDoesNotExist()},
+			{"add an ambiguous member", movies.AddActors, movie, []core.CastMember{{ActorId: martin, ActorName: "Martin Freeman"}}, core.NewErrBadRequest()},
+			{"delete unlinked actors", movies.DeleteActors, movie, []core.CastMember{{ActorId: martin}, {ActorId: benedict}}, core.NewErrActorsNotLinked([]int{martin})},
+			{"delete missing actors", movies.DeleteActors, movie, []core.CastMember{{ActorId: 9}}, core.NewErrActorsDoNotExist([]int{9})},
+			{"delete from a missing movie", movies.DeleteActors, 42, []core.CastMember{{ActorId: benedict}}, core.NewErrMovieDoesNotExist()},
+			{"add nobody", movies.AddActors, 42, nil, core.NewErrBadRequest()},
+			{"delete nobody", movies.DeleteActors, movie, []core.CastMember{}, core.NewErrBadRequest()},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				assertError(t, test.change(ctx, test.movie, test.cast), test.want)
+
+				detail, err := movies.GetMovie(ctx, movie)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{benedict}) {
+					t.Errorf("cast = %v, want it unchanged", got)
+				}
+			})
+		}
+	})
+
+	t.Run("deleted actors are missing", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+
+		if err := actors.DeleteActor(ctx, actor, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		assertError(t, movies.AddActors(ctx, movie, []core.CastMember{{ActorId: actor}}), core.NewErrActorsDoNotExist([]int{actor}))
+		assertError(t, movies.AddActors(ctx, movie, []core.CastMember{{ActorName: "Benedict Cumberbatch"}}), core.NewErrActorNamesDoNotExist([]string{"Benedict Cumberbatch"}))
+	})
+}
+
+// AddActors used to look for a unique violation where Postgres reports a
+// missing actor as a foreign key violation.
+func TestLinkError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		err  error
+		want *core.MyError
+	}{
+		{"missing actor", &pgconn.PgError{Code: pgerrcode.ForeignKeyViolation, Detail: `Key (actor_id)=(5) is not present in table "actors".`}, core.NewErrActorsDoNotExist([]int{5})},
+		{"missing movie", &pgconn.PgError{Code: pgerrcode.ForeignKeyViolation, Detail: `Key (movie_id)=(3) is not present in table "movies".`}, core.NewErrMovieDoesNotExist()},
+		{"linked actor", &pgconn.PgError{Code: pgerrcode.UniqueViolation, Detail: `Key (actor_id, movie_id)=(5, 1) already exists.`}, core.NewErrActorsAlreadyLinked([]int{5})},
+		{"other violation", &pgconn.PgError{Code: pgerrcode.CheckViolation, Detail: `Failing row contains (5, 1).`}, nil},
+		{"not a postgres error", errors.New("connection reset"), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := linkError(context.Background(), test.err)
+
+			if test.want != nil {
+				assertError(t, err, test.want)
+				return
+			}
+
+			var myErr *core.MyError
+			if errors.As(err, &myErr) {
+				t.Errorf("error = %s, want an internal error", myErr.Type)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"filmoteka/internal/core"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepository struct {
+	Db *sqlx.DB
+}
+
+const (
+	DeleteExpiredKeys = "DELETE FROM IdempotencyKeys WHERE expires_at < $1;"
+	ReserveKey        = "INSERT INTO IdempotencyKeys(client, key, request_hash, expires_at) VALUES ($1, $2, $3, $4) ON CONFLICT (client, key) DO NOTHING RETURNING key;"
+	GetKey            = "SELECT request_hash, status_code, content_type, body FROM IdempotencyKeys WHERE client = $1 AND key = $2;"
+	CompleteKey       = "UPDATE IdempotencyKeys SET status_code = $3, content_type = $4, body = $5, expires_at = $6 WHERE client = $1 AND key = $2;"
+	ReleaseKey        = "DELETE FROM IdempotencyKeys WHERE client = $1 AND key = $2 AND status_code IS NULL;"
+)
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{Db: db}
+}
+
+// Reserve claims the key of client for a request until expires. It returns
+// the stored response when the key was already used for the same request.
+func (repository *IdempotencyRepository) Reserve(ctx context.Context, client, key, hash string, expires time.Time) (*core.StoredResponse, error) {
+
+	if _, err := conn(ctx, repository.Db).ExecContext(ctx, DeleteExpiredKeys, time.Now()); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	var reserved string
+	err := conn(ctx, repository.Db).QueryRowContext(ctx, ReserveKey, client, key, hash, expires).Scan(&reserved)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, dbError(ctx, err)
+	}
+
+	var storedHash string
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	var body []byte
+
+	err = conn(ctx, repository.Db).QueryRowContext(ctx, GetKey, client, key).Scan(&storedHash, &statusCode, &contentType, &body)
+	if err == sql.ErrNoRows {
+		// Released by the request holding it between the two queries.
+		return nil, core.NewErrIdempotencyKeyInUse()
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	if storedHash != hash {
+		return nil, core.NewErrIdempotencyKeyReused()
+	}
+
+	if !statusCode.Valid {
+		return nil, core.NewErrIdempotencyKeyInUse()
+	}
+
+	return &core.StoredResponse{StatusCode: int(statusCode.Int64), ContentType: contentType.String, Body: body}, nil
+}
+
+// Complete stores the response to the request holding the key of client
+// until expires.
+func (repository *IdempotencyRepository) Complete(ctx context.Context, client, key string, response *core.StoredResponse, expires time.Time) error {
+
+	_, err := conn(ctx, repository.Db).ExecContext(ctx, CompleteKey, client, key, response.StatusCode, response.ContentType, response.Body, expires)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+// Release frees a key whose request failed, so that it can be retried.
+func (repository *IdempotencyRepository) Release(ctx context.Context, client, key string) error {
+
+	if _, err := conn(ctx, repository.Db).ExecContext(ctx, ReleaseKey, client, key); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
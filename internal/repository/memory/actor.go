@@ -0,0 +1,204 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+type ActorRepository struct {
+	store *Store
+}
+
+// actorColumns are the columns UpdateActor can set. Like the Postgres
+// repository it rejects others before looking at the actor.
+var actorColumns = map[string]bool{"name": true, "sex": true, "bd": true}
+
+func NewActorRepository(store *Store) *ActorRepository {
+	return &ActorRepository{store: store}
+}
+
+func (repository *ActorRepository) CreateActor(ctx context.Context, actor *core.Actor) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	for _, stored := range store.actors {
+		if stored.Name == actor.Name {
+			return core.NewErrActorAlreadyExists()
+		}
+	}
+
+	store.lastActorID++
+	actor.Id = store.lastActorID
+
+	stored := *actor
+	stored.Movies = nil
+	store.actors[actor.Id] = &stored
+	store.touchActor(actor.Id)
+
+	return store.record(ctx, core.AuditActor, actor.Id, core.AuditCreate, nil, store.actor(actor.Id))
+}
+
+func (repository *ActorRepository) UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	if !actorColumns[columnName] {
+		return core.NewErrUnknownColumn()
+	}
+
+	store := repository.store
+	defer store.lock(ctx)()
+
+	actor, ok := store.actors[id]
+	if !ok {
+		return core.NewErrActorDoesNotExist()
+	}
+
+	if err := checkVersion(store.actorVersions[id], version); err != nil {
+		return err
+	}
+
+	updated := *actor
+	value, ok := newValue.(string)
+	if !ok {
+		return errInternal()
+	}
+
+	switch columnName {
+	case "name":
+		for _, stored := range store.actors {
+			if stored.Id != id && stored.Name == value {
+				return core.NewErrActorAlreadyExists()
+			}
+		}
+		updated.Name = value
+	case "sex":
+		if utf8.RuneCountInString(value) != 1 {
+			return errInternal()
+		}
+		updated.Sex, _ = utf8.DecodeRuneInString(value)
+	case "bd":
+		updated.Bd = value
+	default:
+		return core.NewErrUnknownColumn()
+	}
+
+	before := store.actor(id)
+	store.actors[id] = &updated
+	store.touchActor(id)
+
+	return store.record(ctx, core.AuditActor, id, core.AuditUpdate, before, store.actor(id))
+}
+
+func (repository *ActorRepository) DeleteActor(ctx context.Context, id int, version int) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if _, ok := store.actors[id]; !ok {
+		return core.NewErrActorDoesNotExist()
+	}
+
+	if err := checkVersion(store.actorVersions[id], version); err != nil {
+		return err
+	}
+
+	if err := store.record(ctx, core.AuditActor, id, core.AuditDelete, store.actor(id), nil); err != nil {
+		return err
+	}
+
+	store.deletedActors[id] = deleted[*core.Actor]{row: store.actors[id], at: time.Now()}
+	delete(store.actors, id)
+	store.touchActor(id)
+	store.touchActorMovies(id)
+
+	return nil
+}
+
+func (repository *ActorRepository) RestoreActor(ctx context.Context, id int) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if _, ok := store.actors[id]; ok {
+		return nil
+	}
+
+	tombstone, ok := store.deletedActors[id]
+	if !ok {
+		return core.NewErrActorDoesNotExist()
+	}
+
+	for _, stored := range store.actors {
+		if stored.Name == tombstone.row.Name {
+			return core.NewErrActorAlreadyExists()
+		}
+	}
+
+	before := store.actor(id)
+	store.actors[id] = tombstone.row
+	delete(store.deletedActors, id)
+	store.touchActor(id)
+	store.touchActorMovies(id)
+
+	return store.record(ctx, core.AuditActor, id, core.AuditRestore, before, store.actor(id))
+}
+
+func (repository *ActorRepository) PurgeActors(ctx context.Context, before time.Time) (int, error) {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	var ids []int
+	for id, tombstone := range store.deletedActors {
+		if tombstone.at.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		delete(store.deletedActors, id)
+		delete(store.actorVersions, id)
+		for _, actors := range store.links {
+			delete(actors, id)
+		}
+		if err := store.record(ctx, core.AuditActor, id, core.AuditPurge, nil, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+// touchActorMovies bumps the version of every movie linked to the actor.
+// Callers must hold the lock.
+func (store *Store) touchActorMovies(id int) {
+	for movieID, actors := range store.links {
+		if _, ok := actors[id]; ok {
+			store.touchMovie(movieID)
+		}
+	}
+}
+
+func (repository *ActorRepository) GetActor(ctx context.Context, id int) (*core.Actor, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	if _, ok := store.actors[id]; !ok {
+		return nil, core.NewErrActorDoesNotExist()
+	}
+
+	return store.actor(id), nil
+}
+
+func (repository *ActorRepository) GetAllActors(ctx context.Context) ([]*core.Actor, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	actors := make([]*core.Actor, 0, len(store.actors))
+	for id := range store.actors {
+		actors = append(actors, store.actor(id))
+	}
+
+	sort.Slice(actors, func(i, j int) bool { return actors[i].Id < actors[j].Id })
+
+	return actors, nil
+}
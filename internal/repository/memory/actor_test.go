@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+)
+
+func TestPurgeActors(t *testing.T) {
+
+	store := NewStore()
+	movies, actors := NewMovieRepository(store), NewActorRepository(store)
+	ctx := context.Background()
+
+	benedict := &core.Actor{Name: "Benedict Cumberbatch", Sex: 'M', Bd: "1976-07-19"}
+	martin := &core.Actor{Name: "Martin Freeman", Sex: 'M', Bd: "1971-09-08"}
+	for _, actor := range []*core.Actor{benedict, martin} {
+		if err := actors.CreateActor(ctx, actor); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sherlock := &core.Movie{Title: "Sherlock", Descr: "A detective", Release: "2010-07-25", Rating: 9, Actors: []int{benedict.Id, martin.Id}}
+	if err := movies.CreateMovie(ctx, sherlock); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := actors.DeleteActor(ctx, benedict.Id, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.links[sherlock.Id][benedict.Id]; !ok {
+		t.Fatal("links of the deleted actor were dropped before the purge")
+	}
+
+	if purged, err := actors.PurgeActors(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+		t.Fatalf("purged = %d, %v, want Benedict Cumberbatch", purged, err)
+	}
+	if _, ok := store.links[sherlock.Id][benedict.Id]; ok {
+		t.Error("the purged actor is still linked")
+	}
+	if _, ok := store.links[sherlock.Id][martin.Id]; !ok {
+		t.Error("the link of another actor was dropped")
+	}
+}
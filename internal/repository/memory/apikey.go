@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"slices"
+	"sync"
+	"time"
+)
+
+type apiKey struct {
+	key     core.APIKey
+	hash    string
+	revoked bool
+}
+
+// APIKeyRepository keeps API keys apart from the catalog, it needs no Store.
+type APIKeyRepository struct {
+	mu     sync.Mutex
+	keys   []*apiKey
+	lastID int
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{}
+}
+
+func (repository *APIKeyRepository) CreateAPIKey(ctx context.Context, key *core.APIKey, hash string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.lastID++
+	key.Id = repository.lastID
+	key.CreatedAt = time.Now()
+
+	stored := &apiKey{key: *key, hash: hash}
+	stored.key.Key = ""
+	stored.key.Scopes = slices.Clone(key.Scopes)
+	repository.keys = append(repository.keys, stored)
+
+	return nil
+}
+
+func (repository *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]*core.APIKey, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	keys := []*core.APIKey{}
+	for _, stored := range repository.keys {
+		if !stored.revoked {
+			keys = append(keys, stored.copy())
+		}
+	}
+
+	return keys, nil
+}
+
+func (repository *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, stored := range repository.keys {
+		if stored.key.Id == id && !stored.revoked {
+			stored.revoked = true
+			return nil
+		}
+	}
+
+	return core.NewErrAPIKeyDoesNotExist()
+}
+
+func (repository *APIKeyRepository) FindAPIKey(ctx context.Context, hash string) (*core.APIKey, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, stored := range repository.keys {
+		if stored.hash == hash && !stored.revoked {
+			return stored.copy(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (repository *APIKeyRepository) TouchAPIKey(ctx context.Context, id int, at time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, stored := range repository.keys {
+		if stored.key.Id == id {
+			stored.key.LastUsedAt = &at
+		}
+	}
+
+	return nil
+}
+
+func (stored *apiKey) copy() *core.APIKey {
+	key := stored.key
+	key.Scopes = slices.Clone(stored.key.Scopes)
+	return &key
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+)
+
+type AuditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) *AuditRepository {
+	return &AuditRepository{store: store}
+}
+
+func (repository *AuditRepository) ListAudit(ctx context.Context, filter core.AuditFilter) ([]*core.AuditEntry, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	var entries []*core.AuditEntry
+	for _, entry := range store.audit {
+		if len(entries) == filter.Limit {
+			break
+		}
+		if entry.Id <= filter.After ||
+			(filter.Entity != "" && entry.Entity != filter.Entity) ||
+			(filter.EntityId != 0 && entry.EntityId != filter.EntityId) {
+			continue
+		}
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+
+	return entries, nil
+}
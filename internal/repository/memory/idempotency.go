@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"sync"
+	"time"
+)
+
+// clientKey is an idempotency key as scoped to the client sending it.
+type clientKey struct {
+	client, key string
+}
+
+type idempotencyKey struct {
+	hash     string
+	response *core.StoredResponse
+	expires  time.Time
+}
+
+// IdempotencyRepository keeps idempotency keys apart from the catalog, it
+// needs no Store.
+type IdempotencyRepository struct {
+	mu   sync.Mutex
+	keys map[clientKey]*idempotencyKey
+}
+
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{keys: map[clientKey]*idempotencyKey{}}
+}
+
+func (repository *IdempotencyRepository) Reserve(ctx context.Context, client, key, hash string, expires time.Time) (*core.StoredResponse, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for k, stored := range repository.keys {
+		if stored.expires.Before(now) {
+			delete(repository.keys, k)
+		}
+	}
+
+	stored, ok := repository.keys[clientKey{client, key}]
+	if !ok {
+		repository.keys[clientKey{client, key}] = &idempotencyKey{hash: hash, expires: expires}
+		return nil, nil
+	}
+
+	if stored.hash != hash {
+		return nil, core.NewErrIdempotencyKeyReused()
+	}
+
+	if stored.response == nil {
+		return nil, core.NewErrIdempotencyKeyInUse()
+	}
+
+	response := *stored.response
+	return &response, nil
+}
+
+func (repository *IdempotencyRepository) Complete(ctx context.Context, client, key string, response *core.StoredResponse, expires time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if stored, ok := repository.keys[clientKey{client, key}]; ok {
+		stored.response = response
+		stored.expires = expires
+	}
+
+	return nil
+}
+
+func (repository *IdempotencyRepository) Release(ctx context.Context, client, key string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if stored, ok := repository.keys[clientKey{client, key}]; ok && stored.response == nil {
+		delete(repository.keys, clientKey{client, key})
+	}
+
+	return nil
+}
@@ -0,0 +1,439 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MovieRepository struct {
+	store *Store
+}
+
+// movieColumns are the columns UpdateMovie can set. Like the Postgres
+// repository it rejects others before looking at the movie.
+var movieColumns = map[string]bool{"title": true, "descr": true, "release": true, "rating": true}
+
+func NewMovieRepository(store *Store) *MovieRepository {
+	return &MovieRepository{store: store}
+}
+
+func (repository *MovieRepository) CreateMovie(ctx context.Context, movie *core.Movie) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if movie.Title == "" {
+		return errInternal()
+	}
+
+	for _, stored := range store.movies {
+		if stored.Title == movie.Title {
+			return core.NewErrMovieAlreadyExists()
+		}
+	}
+
+	if missing := store.missingActors(movie.Actors); len(missing) > 0 {
+		return core.NewErrActorsDoNotExist(missing)
+	}
+
+	store.lastMovieID++
+	movie.Id = store.lastMovieID
+
+	stored := *movie
+	stored.Actors = nil
+	store.movies[movie.Id] = &stored
+
+	store.links[movie.Id] = map[int]core.CastMember{}
+	for _, actorID := range movie.Actors {
+		store.links[movie.Id][actorID] = core.CastMember{ActorId: actorID}
+		store.touchActor(actorID)
+	}
+
+	store.touchMovie(movie.Id)
+
+	return store.record(ctx, core.AuditMovie, movie.Id, core.AuditCreate, nil, store.movie(movie.Id))
+}
+
+func (repository *MovieRepository) DeleteMovie(ctx context.Context, id int, version int) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if _, ok := store.movies[id]; !ok {
+		return core.NewErrMovieDoesNotExist()
+	}
+
+	if err := checkVersion(store.movieVersions[id], version); err != nil {
+		return err
+	}
+
+	if err := store.record(ctx, core.AuditMovie, id, core.AuditDelete, store.movie(id), nil); err != nil {
+		return err
+	}
+
+	for actorID := range store.links[id] {
+		store.touchActor(actorID)
+	}
+
+	store.deletedMovies[id] = deleted[*core.Movie]{row: store.movies[id], at: time.Now()}
+	delete(store.movies, id)
+	store.touchMovie(id)
+
+	return nil
+}
+
+func (repository *MovieRepository) RestoreMovie(ctx context.Context, id int) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if _, ok := store.movies[id]; ok {
+		return nil
+	}
+
+	tombstone, ok := store.deletedMovies[id]
+	if !ok {
+		return core.NewErrMovieDoesNotExist()
+	}
+
+	for _, stored := range store.movies {
+		if stored.Title == tombstone.row.Title {
+			return core.NewErrMovieAlreadyExists()
+		}
+	}
+
+	before := store.movie(id)
+	store.movies[id] = tombstone.row
+	delete(store.deletedMovies, id)
+	store.touchMovie(id)
+	for actorID := range store.links[id] {
+		store.touchActor(actorID)
+	}
+
+	return store.record(ctx, core.AuditMovie, id, core.AuditRestore, before, store.movie(id))
+}
+
+func (repository *MovieRepository) PurgeMovies(ctx context.Context, before time.Time) (int, error) {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	var ids []int
+	for id, tombstone := range store.deletedMovies {
+		if tombstone.at.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		delete(store.deletedMovies, id)
+		delete(store.links, id)
+		delete(store.movieVersions, id)
+		if err := store.record(ctx, core.AuditMovie, id, core.AuditPurge, nil, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+func (repository *MovieRepository) UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	if !movieColumns[columnName] {
+		return core.NewErrUnknownColumn()
+	}
+
+	store := repository.store
+	defer store.lock(ctx)()
+
+	movie, ok := store.movies[id]
+	if !ok {
+		return core.NewErrMovieDoesNotExist()
+	}
+
+	if err := checkVersion(store.movieVersions[id], version); err != nil {
+		return err
+	}
+
+	updated := *movie
+
+	switch columnName {
+	case "title":
+		title, ok := newValue.(string)
+		if !ok || title == "" {
+			return errInternal()
+		}
+		for _, stored := range store.movies {
+			if stored.Id != id && stored.Title == title {
+				return core.NewErrMovieAlreadyExists()
+			}
+		}
+		updated.Title = title
+	case "descr":
+		descr, ok := newValue.(string)
+		if !ok {
+			return errInternal()
+		}
+		updated.Descr = descr
+	case "release":
+		release, ok := newValue.(string)
+		if !ok {
+			return errInternal()
+		}
+		updated.Release = release
+	case "rating":
+		rating, ok := newValue.(int)
+		if !ok {
+			return errInternal()
+		}
+		updated.Rating = rating
+	default:
+		return core.NewErrUnknownColumn()
+	}
+
+	before := store.movie(id)
+	store.movies[id] = &updated
+	store.touchMovie(id)
+
+	return store.record(ctx, core.AuditMovie, id, core.AuditUpdate, before, store.movie(id))
+}
+
+func (repository *MovieRepository) AddActors(ctx context.Context, id int, cast []core.CastMember) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	cast, err := store.resolveCast(id, cast)
+	if err != nil {
+		return err
+	}
+
+	var linked []int
+	for _, member := range cast {
+		if _, ok := store.links[id][member.ActorId]; ok {
+			linked = append(linked, member.ActorId)
+		}
+	}
+
+	if len(linked) > 0 {
+		return core.NewErrActorsAlreadyLinked(linked)
+	}
+
+	before := store.movie(id)
+	for _, member := range cast {
+		store.links[id][member.ActorId] = core.CastMember{ActorId: member.ActorId, Character: member.Character, Billing: member.Billing}
+		store.touchActor(member.ActorId)
+	}
+
+	store.touchMovie(id)
+
+	return store.record(ctx, core.AuditMovie, id, core.AuditLink, before, store.movie(id))
+}
+
+func (repository *MovieRepository) DeleteActors(ctx context.Context, id int, cast []core.CastMember) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	cast, err := store.resolveCast(id, cast)
+	if err != nil {
+		return err
+	}
+
+	var notLinked []int
+	for _, member := range cast {
+		if _, ok := store.links[id][member.ActorId]; !ok {
+			notLinked = append(notLinked, member.ActorId)
+		}
+	}
+
+	if len(notLinked) > 0 {
+		return core.NewErrActorsNotLinked(notLinked)
+	}
+
+	before := store.movie(id)
+	for _, member := range cast {
+		delete(store.links[id], member.ActorId)
+		store.touchActor(member.ActorId)
+	}
+
+	store.touchMovie(id)
+
+	return store.record(ctx, core.AuditMovie, id, core.AuditUnlink, before, store.movie(id))
+}
+
+// resolveCast refuses an empty cast, resolves actor names to ids and checks
+// that the movie and every actor exist. The returned cast holds each actor
+// once.
+// Callers must hold the lock.
+func (store *Store) resolveCast(movieID int, cast []core.CastMember) ([]core.CastMember, error) {
+	if len(cast) == 0 {
+		return nil, core.NewErrBadRequest()
+	}
+
+	for _, member := range cast {
+		if !member.Valid() {
+			return nil, core.NewErrBadRequest()
+		}
+	}
+
+	if _, ok := store.movies[movieID]; !ok {
+		return nil, core.NewErrMovieDoesNotExist()
+	}
+
+	var unknown []string
+	var ids []int
+	resolved := make([]core.CastMember, 0, len(cast))
+	seen := map[int]struct{}{}
+	for _, member := range cast {
+		if member.ActorId == 0 {
+			actorID, ok := store.actorByName(member.ActorName)
+			if !ok {
+				unknown = append(unknown, member.ActorName)
+				continue
+			}
+			member.ActorId = actorID
+		}
+		if _, ok := seen[member.ActorId]; !ok {
+			seen[member.ActorId] = struct{}{}
+			ids = append(ids, member.ActorId)
+			resolved = append(resolved, member)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, core.NewErrActorNamesDoNotExist(unknown)
+	}
+
+	if missing := store.missingActors(ids); len(missing) > 0 {
+		return nil, core.NewErrActorsDoNotExist(missing)
+	}
+
+	return resolved, nil
+}
+
+// actorByName finds an actor by exact name. Callers must hold the lock.
+func (store *Store) actorByName(name string) (int, bool) {
+	for id, actor := range store.actors {
+		if actor.Name == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// missingActors returns the sorted ids that match no actor.
+// Callers must hold the lock.
+func (store *Store) missingActors(ids []int) []int {
+	missing := map[int]struct{}{}
+	for _, id := range ids {
+		if _, ok := store.actors[id]; !ok {
+			missing[id] = struct{}{}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return sortedIDs(missing)
+}
+
+func (repository *MovieRepository) GetMovie(ctx context.Context, id int) (*core.MovieDetail, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	movie, ok := store.movies[id]
+	if !ok {
+		return nil, core.NewErrMovieDoesNotExist()
+	}
+
+	detail := &core.MovieDetail{
+		Id:      movie.Id,
+		Title:   movie.Title,
+		Descr:   movie.Descr,
+		Release: movie.Release,
+		Rating:  movie.Rating,
+		Actors:  []*core.CastActor{},
+		Version: store.movieVersions[id],
+	}
+
+	versions := []string{}
+	for _, actorID := range billedIDs(store.cast(id)) {
+		actor, member := store.actors[actorID], store.links[id][actorID]
+		detail.Actors = append(detail.Actors, &core.CastActor{
+			Id:        actor.Id,
+			Name:      actor.Name,
+			Sex:       actor.Sex,
+			Bd:        actor.Bd,
+			Character: member.Character,
+			Billing:   member.Billing,
+		})
+		versions = append(versions, strconv.Itoa(actorID)+":"+strconv.Itoa(store.actorVersions[actorID]))
+	}
+	detail.CastVersion = strings.Join(versions, ",")
+
+	return detail, nil
+}
+
+func (repository *MovieRepository) GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error) {
+	return repository.sorted(ctx, func(a, b *core.Movie) bool { return a.Rating > b.Rating }), nil
+}
+
+func (repository *MovieRepository) GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error) {
+	return repository.sorted(ctx, func(a, b *core.Movie) bool { return a.Title < b.Title }), nil
+}
+
+func (repository *MovieRepository) GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error) {
+	return repository.sorted(ctx, func(a, b *core.Movie) bool { return a.Release > b.Release }), nil
+}
+
+func (repository *MovieRepository) SearchMovie(ctx context.Context, search string) ([]*core.Movie, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	search = strings.ToLower(search)
+
+	var movies []*core.Movie
+	for id := range store.movies {
+		movie := store.movie(id)
+		if strings.Contains(strings.ToLower(movie.Title), search) || store.hasActorLike(movie.Actors, search) {
+			movies = append(movies, movie)
+		}
+	}
+
+	sort.Slice(movies, func(i, j int) bool { return movies[i].Id < movies[j].Id })
+
+	return movies, nil
+}
+
+// hasActorLike reports whether one of the actors' names contains search.
+// Callers must hold the lock.
+func (store *Store) hasActorLike(actorIDs []int, search string) bool {
+	for _, id := range actorIDs {
+		if strings.Contains(strings.ToLower(store.actors[id].Name), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// sorted returns all movies ordered by less, ties are broken by id.
+func (repository *MovieRepository) sorted(ctx context.Context, less func(a, b *core.Movie) bool) []*core.Movie {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	movies := make([]*core.Movie, 0, len(store.movies))
+	for id := range store.movies {
+		movies = append(movies, store.movie(id))
+	}
+
+	sort.Slice(movies, func(i, j int) bool {
+		if less(movies[i], movies[j]) {
+			return true
+		}
+		if less(movies[j], movies[i]) {
+			return false
+		}
+		return movies[i].Id < movies[j].Id
+	})
+
+	return movies
+}
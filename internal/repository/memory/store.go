@@ -0,0 +1,195 @@
+// Package memory keeps movies and actors in process memory. It mirrors the
+// constraints of the Postgres schema and is meant for tests and demo mode.
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store is the state shared by MovieRepository and ActorRepository, so that
+// links between movies and actors behave like the ActorMovie table.
+type Store struct {
+	mu sync.RWMutex
+
+	movies map[int]*core.Movie
+	actors map[int]*core.Actor
+
+	// deletedMovies and deletedActors keep deleted rows until they are
+	// purged.
+	deletedMovies map[int]deleted[*core.Movie]
+	deletedActors map[int]deleted[*core.Actor]
+
+	// links maps a movie id to its cast keyed by actor id. Links of deleted
+	// rows are kept so that a restore brings them back.
+	links map[int]map[int]core.CastMember
+
+	lastMovieID int
+	lastActorID int
+
+	// movieVersions and actorVersions count the changes of each row like
+	// the version columns do.
+	movieVersions map[int]int
+	actorVersions map[int]int
+
+	audit []*core.AuditEntry
+
+	webhooks      map[int]*core.Webhook
+	lastWebhookID int
+	// deliveries is the webhook outbox, a delivery's id is its index + 1.
+	deliveries []*core.WebhookDelivery
+}
+
+type deleted[T any] struct {
+	row T
+	at  time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		movies: map[int]*core.Movie{},
+		actors: map[int]*core.Actor{},
+		links:  map[int]map[int]core.CastMember{},
+
+		deletedMovies: map[int]deleted[*core.Movie]{},
+		deletedActors: map[int]deleted[*core.Actor]{},
+
+		movieVersions: map[int]int{},
+		actorVersions: map[int]int{},
+
+		webhooks: map[int]*core.Webhook{},
+	}
+}
+
+// record appends an entry to the audit log and queues the event it
+// publishes for webhooks. Callers must hold the lock.
+func (store *Store) record(ctx context.Context, entity string, id int, operation string, before, after interface{}) error {
+	beforeJSON, err := core.AuditSnapshot(before)
+	if err != nil {
+		return errInternal()
+	}
+
+	afterJSON, err := core.AuditSnapshot(after)
+	if err != nil {
+		return errInternal()
+	}
+
+	entry := &core.AuditEntry{
+		Id:        int64(len(store.audit) + 1),
+		User:      core.UserFrom(ctx),
+		At:        time.Now(),
+		Entity:    entity,
+		EntityId:  id,
+		Operation: operation,
+		Before:    beforeJSON,
+		After:     afterJSON,
+	}
+	store.audit = append(store.audit, entry)
+
+	return store.queueDeliveries(entry)
+}
+
+func errInternal() error {
+	return fmt.Errorf("Internal server error")
+}
+
+// movie returns a copy of the stored movie, deleted or not, with the ids of
+// its live actors filled in. Callers must hold the lock.
+func (store *Store) movie(id int) *core.Movie {
+	row, ok := store.movies[id]
+	if !ok {
+		row = store.deletedMovies[id].row
+	}
+	stored := *row
+	stored.Actors = billedIDs(store.cast(id))
+	return &stored
+}
+
+// cast returns the links of a movie to live actors. Callers must hold the
+// lock.
+func (store *Store) cast(movieID int) map[int]core.CastMember {
+	cast := map[int]core.CastMember{}
+	for actorID, member := range store.links[movieID] {
+		if _, ok := store.actors[actorID]; ok {
+			cast[actorID] = member
+		}
+	}
+	return cast
+}
+
+// actor returns a copy of the stored actor, deleted or not, with the ids of
+// its live movies filled in. Callers must hold the lock.
+func (store *Store) actor(id int) *core.Actor {
+	row, ok := store.actors[id]
+	if !ok {
+		row = store.deletedActors[id].row
+	}
+	stored := *row
+	movies := map[int]struct{}{}
+	for movieID, actors := range store.links {
+		if _, ok := actors[id]; ok && store.movies[movieID] != nil {
+			movies[movieID] = struct{}{}
+		}
+	}
+	stored.Movies = sortedIDs(movies)
+	stored.Version = store.actorVersions[id]
+	return &stored
+}
+
+// touchMovie and touchActor bump the version of a row, a new row starts at 1.
+// Callers must hold the lock.
+func (store *Store) touchMovie(id int) {
+	store.movieVersions[id]++
+}
+
+func (store *Store) touchActor(id int) {
+	store.actorVersions[id]++
+}
+
+// checkVersion compares the stored version of a row with the expected one
+// unless expected is 0.
+func checkVersion(stored, expected int) error {
+	if expected != 0 && stored != expected {
+		return core.NewErrVersionMismatch()
+	}
+	return nil
+}
+
+func sortedIDs(set map[int]struct{}) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// billedIDs orders actor ids like the Postgres queries do: billed actors
+// first by billing order, then the rest by id.
+func billedIDs(cast map[int]core.CastMember) []int {
+	members := make([]core.CastMember, 0, len(cast))
+	for _, member := range cast {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if (a.Billing == 0) != (b.Billing == 0) {
+			return b.Billing == 0
+		}
+		if a.Billing != b.Billing {
+			return a.Billing < b.Billing
+		}
+		return a.ActorId < b.ActorId
+	})
+
+	ids := make([]int, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ActorId)
+	}
+	return ids
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"maps"
+)
+
+type txKey struct{}
+
+// lock takes the write lock of the store unless ctx runs inside one of its
+// transactions, which already holds it. It returns the matching unlock.
+func (store *Store) lock(ctx context.Context) func() {
+	if ctx.Value(txKey{}) == store {
+		return func() {}
+	}
+	store.mu.Lock()
+	return store.mu.Unlock
+}
+
+// rlock is lock for reads.
+func (store *Store) rlock(ctx context.Context) func() {
+	if ctx.Value(txKey{}) == store {
+		return func() {}
+	}
+	store.mu.RLock()
+	return store.mu.RUnlock
+}
+
+// Transactor runs units of work against a Store. A transaction holds the
+// write lock of the store for its whole duration, which makes it
+// serializable, and puts the previous state back when it fails.
+type Transactor struct {
+	store *Store
+}
+
+func NewTransactor(store *Store) *Transactor {
+	return &Transactor{store: store}
+}
+
+func (transactor *Transactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	store := transactor.store
+
+	if ctx.Value(txKey{}) == store {
+		return fn(ctx)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	saved := store.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, store)); err != nil {
+		store.restore(saved)
+		return err
+	}
+
+	return nil
+}
+
+// storeState is what a failed transaction restores. Rows are replaced
+// rather than changed in place, so copying the maps is enough; only the
+// per-movie link maps are modified in place and are copied one by one. The
+// audit log and the webhook outbox only grow within a transaction.
+type storeState struct {
+	movies        map[int]*core.Movie
+	actors        map[int]*core.Actor
+	deletedMovies map[int]deleted[*core.Movie]
+	deletedActors map[int]deleted[*core.Actor]
+	links         map[int]map[int]core.CastMember
+	lastMovieID   int
+	lastActorID   int
+	movieVersions map[int]int
+	actorVersions map[int]int
+	audit         int
+	deliveries    int
+}
+
+func (store *Store) snapshot() *storeState {
+	links := make(map[int]map[int]core.CastMember, len(store.links))
+	for movieID, cast := range store.links {
+		links[movieID] = maps.Clone(cast)
+	}
+
+	return &storeState{
+		movies:        maps.Clone(store.movies),
+		actors:        maps.Clone(store.actors),
+		deletedMovies: maps.Clone(store.deletedMovies),
+		deletedActors: maps.Clone(store.deletedActors),
+		links:         links,
+		lastMovieID:   store.lastMovieID,
+		lastActorID:   store.lastActorID,
+		movieVersions: maps.Clone(store.movieVersions),
+		actorVersions: maps.Clone(store.actorVersions),
+		audit:         len(store.audit),
+		deliveries:    len(store.deliveries),
+	}
+}
+
+func (store *Store) restore(state *storeState) {
+	store.movies = state.movies
+	store.actors = state.actors
+	store.deletedMovies = state.deletedMovies
+	store.deletedActors = state.deletedActors
+	store.links = state.links
+	store.lastMovieID = state.lastMovieID
+	store.lastActorID = state.lastActorID
+	store.movieVersions = state.movieVersions
+	store.actorVersions = state.actorVersions
+	store.audit = store.audit[:state.audit]
+	store.deliveries = store.deliveries[:state.deliveries]
+}
@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"filmoteka/internal/core"
+	"slices"
+	"time"
+)
+
+type WebhookRepository struct {
+	store *Store
+}
+
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+func (repository *WebhookRepository) CreateWebhook(ctx context.Context, webhook *core.Webhook) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	store.lastWebhookID++
+	webhook.Id = store.lastWebhookID
+	webhook.CreatedAt = time.Now()
+
+	stored := *webhook
+	stored.Events = slices.Clone(webhook.Events)
+	store.webhooks[webhook.Id] = &stored
+
+	return nil
+}
+
+func (repository *WebhookRepository) ListWebhooks(ctx context.Context) ([]*core.Webhook, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	webhooks := make([]*core.Webhook, 0, len(store.webhooks))
+	for _, stored := range store.webhooks {
+		webhook := *stored
+		webhook.Secret = ""
+		webhooks = append(webhooks, &webhook)
+	}
+	slices.SortFunc(webhooks, func(a, b *core.Webhook) int { return a.Id - b.Id })
+
+	return webhooks, nil
+}
+
+func (repository *WebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	if _, ok := store.webhooks[id]; !ok {
+		return core.NewErrWebhookDoesNotExist()
+	}
+	delete(store.webhooks, id)
+
+	// Deliveries keep their slots so that ids stay indexes.
+	for i, delivery := range store.deliveries {
+		if delivery != nil && delivery.WebhookId == id {
+			store.deliveries[i] = nil
+		}
+	}
+
+	return nil
+}
+
+func (repository *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]*core.WebhookDelivery, error) {
+	store := repository.store
+	defer store.rlock(ctx)()
+
+	if _, ok := store.webhooks[webhookID]; !ok {
+		return nil, core.NewErrWebhookDoesNotExist()
+	}
+
+	deliveries := []*core.WebhookDelivery{}
+	for i := len(store.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		delivery := store.deliveries[i]
+		if delivery == nil || delivery.WebhookId != webhookID || (status != "" && delivery.Status != status) {
+			continue
+		}
+		copied := *delivery
+		deliveries = append(deliveries, &copied)
+	}
+
+	return deliveries, nil
+}
+
+func (repository *WebhookRepository) RetryDelivery(ctx context.Context, webhookID int, id int64) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	delivery := store.delivery(id)
+	if delivery == nil || delivery.WebhookId != webhookID {
+		return core.NewErrDeliveryDoesNotExist()
+	}
+	if delivery.Status != core.DeliveryDead {
+		return core.NewErrDeliveryNotDead()
+	}
+
+	delivery.Status = core.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+
+	return nil
+}
+
+func (repository *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Time) ([]*core.WebhookDelivery, error) {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	now := time.Now()
+	var deliveries []*core.WebhookDelivery
+	for _, delivery := range store.deliveries {
+		if len(deliveries) == limit {
+			break
+		}
+		if delivery == nil || delivery.Status != core.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		delivery.NextAttemptAt = lease
+		copied := *delivery
+		webhook := store.webhooks[delivery.WebhookId]
+		copied.URL, copied.Secret = webhook.URL, webhook.Secret
+		deliveries = append(deliveries, &copied)
+	}
+
+	return deliveries, nil
+}
+
+func (repository *WebhookRepository) CompleteDelivery(ctx context.Context, delivery *core.WebhookDelivery) error {
+	store := repository.store
+	defer store.lock(ctx)()
+
+	stored := store.delivery(delivery.Id)
+	if stored == nil {
+		return nil
+	}
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastError = delivery.LastError
+
+	return nil
+}
+
+// delivery returns the stored delivery with the given id or nil. Callers
+// must hold the lock.
+func (store *Store) delivery(id int64) *core.WebhookDelivery {
+	if id < 1 || id > int64(len(store.deliveries)) {
+		return nil
+	}
+	return store.deliveries[id-1]
+}
+
+// queueDeliveries adds the event an audit entry publishes to the outbox of
+// every webhook subscribed to it. Callers must hold the lock.
+func (store *Store) queueDeliveries(entry *core.AuditEntry) error {
+	event := core.EventFromAudit(entry)
+	if event == nil {
+		return nil
+	}
+
+	var payload []byte
+	for _, webhook := range store.webhooks {
+		if !slices.Contains(webhook.Events, event.Name()) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				return errInternal()
+			}
+		}
+
+		store.deliveries = append(store.deliveries, &core.WebhookDelivery{
+			Id:            int64(len(store.deliveries) + 1),
+			WebhookId:     webhook.Id,
+			EventId:       event.Id,
+			EventType:     event.Name(),
+			Payload:       payload,
+			Status:        core.DeliveryPending,
+			NextAttemptAt: entry.At,
+			CreatedAt:     entry.At,
+		})
+	}
+
+	return nil
+}
@@ -4,58 +4,101 @@ import (
 	"context"
 	"errors"
 	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/jmoiron/sqlx"
-	log "github.com/sirupsen/logrus"
-
-	"strings"
 
 	"database/sql"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type MovieRepository struct {
-	Db *sqlx.DB
+	Db       *sqlx.DB
+	Replicas *Replicas
 }
 
 const (
 	UniqueViolationErr  = "23505"
 	ForeignKeyViolation = "23503"
 
-	CreateMovie = "INSERT INTO Movies(title, descr, release, rating) SELECT $1, $2, $3, $4 returning id;"
-	UpdateMovie = "UPDATE movies SET $1 = $2 where id = $3;"
+	CreateMovie  = "INSERT INTO Movies(title, descr, release, rating) SELECT $1, $2, $3, $4 returning id;"
+	UpdateMovie  = "UPDATE Movies SET %s = $1, version = version + 1, updated_at = now() where id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3);"
+	MovieVersion = "SELECT version FROM Movies WHERE id = $1 AND deleted_at IS NULL;"
 
-	AddActorsToMovie      = "SELECT add_actors_to_movie($1, $2);"
+	AddActorsToMovie      = "SELECT add_actors_to_movie($1, $2, $3, $4);"
 	DeleteActorsFromMovie = "SELECT delete_actors_from_movie($1, $2);"
-	DeleteMovie           = "DELETE FROM Actors where id = ($1) returning *;"
-
-	SortMoviesByRating = ` SELECT id, title, descr, release, rating, array_agg(a.names) AS actors FROM Movies m LEFT JOIN ActorMovie am ON m.id = am.movie_id 
-	LEFT JOIN Actors a ON am.actor_id = a.id GROUP BY m.id, m.title, m.descr, m.release, m.rating ORDER BY m.rating DESC;`
-
-	SortMoviesByReleaseDate = ` SELECT id, title, descr, release, rating, array_agg(a.names) AS actors FROM Movies m LEFT JOIN ActorMovie am ON m.id = am.movie_id
-	LEFT JOIN Actors a ON am.actor_id = a.id GROUP BY m.id, m.title, m.descr, m.release, m.rating ORDER BY m.release DESC;`
-
-	SortMoviesByTitle = `SELECT id, title, descr, release, rating, array_agg(a.names) AS actors FROM Movies m LEFT JOIN ActorMovie am ON m.id = am.movie_id 
-	LEFT JOIN Actors a ON am.actor_id = a.id GROUP BY m.id, m.title, m.descr, m.release, m.rating ORDER BY m.title;`
-
-	SearchMovie = `SELECT m.id AS movie_id, m.title AS movie_title, m.descr, m.release, m.rating, array_agg(a.names) AS actors FROM Movies m
-	LEFT JOIN ActorMovie am ON m.id = am.movie_id LEFT JOIN Actors a ON am.actor_id = a.id WHERE m.title ILIKE '%' || $1 || '%' OR a.names ILIKE '%' || $1 || '%'
-	GROUP BY m.id, m.title, m.descr, m.release, m.rating;`
+	LockMovie             = "SELECT id FROM Movies WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;"
+	TouchMovie            = "UPDATE Movies SET version = version + 1, updated_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id;"
+	TouchMovieActors      = "UPDATE Actors SET version = version + 1, updated_at = now() WHERE id IN (SELECT actor_id FROM ActorMovie WHERE movie_id = $1);"
+	MissingActors         = "SELECT ids.id FROM unnest($1::int[]) AS ids(id) WHERE NOT EXISTS (SELECT 1 FROM Actors a WHERE a.id = ids.id AND a.deleted_at IS NULL) ORDER BY ids.id;"
+	LinkedActors          = "SELECT actor_id FROM ActorMovie WHERE movie_id = $2 AND actor_id = ANY($1) ORDER BY actor_id;"
+	ActorsByName          = "SELECT names, id FROM Actors WHERE names = ANY($1) AND deleted_at IS NULL;"
+
+	// Deleted movies keep their cast links so that a restore brings them back.
+	DeleteMovie     = "UPDATE Movies SET deleted_at = now(), version = version + 1, updated_at = now() WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2) RETURNING id;"
+	LockAnyMovie    = "SELECT deleted_at IS NOT NULL FROM Movies WHERE id = $1 FOR UPDATE;"
+	RestoreMovie    = "UPDATE Movies SET deleted_at = NULL, version = version + 1, updated_at = now() WHERE id = $1;"
+	ExpiredMovies   = "SELECT id FROM Movies WHERE deleted_at < $1 ORDER BY id FOR UPDATE;"
+	PurgeMovieLinks = "DELETE FROM ActorMovie WHERE movie_id = ANY($1);"
+	PurgeMovies     = "DELETE FROM Movies WHERE id = ANY($1);"
+
+	// liveCast joins the links of a movie to actors that are not deleted.
+	liveCast = "LEFT JOIN (ActorMovie am JOIN Actors a ON a.id = am.actor_id AND a.deleted_at IS NULL) ON am.movie_id = m.id"
+
+	castActorIDs = "COALESCE(array_agg(am.actor_id ORDER BY am.billing_order NULLS LAST, am.actor_id) FILTER (WHERE am.actor_id IS NOT NULL), '{}') AS actors"
+
+	// GetMovie returns a row per cast member, or a single row with NULL actor
+	// columns for a movie without cast.
+	GetMovie = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, m.version,
+	a.id, a.names, a.sex, a.bd::text, am.character_name, am.billing_order, a.id || ':' || a.version
+	FROM Movies m ` + liveCast + `
+	WHERE m.id = $1 AND m.deleted_at IS NULL ORDER BY am.billing_order NULLS LAST, a.id;`
+
+	// MovieSnapshot also finds deleted movies, which are restored and purged.
+	MovieSnapshot = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, ` + castActorIDs + `
+	FROM Movies m ` + liveCast + ` WHERE m.id = $1 GROUP BY m.id;`
+
+	SortMoviesByRating = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, ` + castActorIDs + `
+	FROM Movies m ` + liveCast + ` WHERE m.deleted_at IS NULL GROUP BY m.id ORDER BY m.rating DESC;`
+
+	SortMoviesByReleaseDate = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, ` + castActorIDs + `
+	FROM Movies m ` + liveCast + ` WHERE m.deleted_at IS NULL GROUP BY m.id ORDER BY m.release DESC;`
+
+	SortMoviesByTitle = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, ` + castActorIDs + `
+	FROM Movies m ` + liveCast + ` WHERE m.deleted_at IS NULL GROUP BY m.id ORDER BY m.title;`
+
+	SearchMovie = `SELECT m.id, m.title, m.descr, m.release::text, m.rating, ` + castActorIDs + `
+	FROM Movies m ` + liveCast + ` WHERE m.deleted_at IS NULL AND (m.title ILIKE '%' || $1 || '%'
+	OR EXISTS (SELECT 1 FROM ActorMovie sam JOIN Actors sa ON sam.actor_id = sa.id WHERE sam.movie_id = m.id AND sa.deleted_at IS NULL AND sa.names ILIKE '%' || $1 || '%'))
+	GROUP BY m.id;`
 )
 
-func NewMovieRepository(db *sqlx.DB) *MovieRepository {
-	return &MovieRepository{Db: db}
+// movieColumns maps updatable fields of core.Movie to Movies columns.
+var movieColumns = map[string]string{
+	"title":   "title",
+	"descr":   "descr",
+	"release": "release",
+	"rating":  "rating",
+}
+
+// NewMovieRepository sends the list and search queries to replicas, or to db
+// when replicas is nil.
+func NewMovieRepository(db *sqlx.DB, replicas *Replicas) *MovieRepository {
+	return &MovieRepository{Db: db, Replicas: replicas}
 }
 
 func (repository *MovieRepository) CreateMovie(ctx context.Context, movie *core.Movie) error {
 
-	tx, err := repository.Db.Begin()
+	tx, err := begin(ctx, repository.Db)
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	defer tx.Rollback()
@@ -66,222 +109,402 @@ func (repository *MovieRepository) CreateMovie(ctx context.Context, movie *core.
 
 	var e *pgconn.PgError
 	if err != nil {
-		log.Info(err.Error())
 		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
 			return core.NewErrMovieAlreadyExists()
 		}
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
-	_, err = tx.ExecContext(ctx, AddActorsToMovie, movie.Actors, movie.Id)
+	movie.Actors = uniqueIDs(movie.Actors)
 
+	missing, err := queryIDs(ctx, tx, MissingActors, movie.Actors)
 	if err != nil {
-		log.Info(err.Error())
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			var nonExistentActorID int
-			_, err := fmt.Sscanf(e.Detail, "Key (actor_id)=(%d)", &nonExistentActorID)
-			newerr := core.NewErrActorDoesNotExist()
-			newerr.Inf.Msg += fmt.Sprintf("Non-existent actor ID: %d\n", nonExistentActorID)
-			log.Info(err.Error())
-			return newerr
-		}
-		return fmt.Errorf("Internal server error")
+		return err
+	}
+
+	if len(missing) > 0 {
+		return core.NewErrActorsDoNotExist(missing)
+	}
+
+	_, err = tx.ExecContext(ctx, AddActorsToMovie, movie.Actors, []string{}, []int{}, movie.Id)
+
+	if err != nil {
+		return linkError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, TouchActors, movie.Actors)
+
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, movie.Id, core.AuditCreate, nil, movie); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	return nil
 
 }
 
-func (repository *MovieRepository) DeleteMovie() error {
-	return nil
-}
+// DeleteMovie marks a movie as deleted unless version is set and differs
+// from the stored one. The movie can be restored until it is purged.
+func (repository *MovieRepository) DeleteMovie(ctx context.Context, id int, version int) error {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
 
-func (repository *MovieRepository) UpdateMovie(ctx context.Context, id int, columnName string, newValue interface{}) error {
-	res, err := repository.Db.ExecContext(ctx, UpdateMovie, columnName, newValue, id)
+	if err = lockRow(ctx, tx, LockMovie, id, core.NewErrMovieDoesNotExist()); err != nil {
+		return err
+	}
+
+	before, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, TouchMovieActors, id)
 
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
+	}
+
+	res, err := tx.ExecContext(ctx, DeleteMovie, id, version)
+
+	if err != nil {
+		return dbError(ctx, err)
 	}
 
 	rows, err := res.RowsAffected()
 	if err != nil {
-		log.Info(err.Error())
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
 	}
 
 	if rows == 0 {
-		log.Info("No rows affected")
-		return core.NewErrMovieDoesNotExist()
+		return versionError(ctx, tx, MovieVersion, id, core.NewErrMovieDoesNotExist())
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, id, core.AuditDelete, before, nil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
 	}
 
 	return nil
 
 }
 
-func (repository *MovieRepository) AddActors(ctx context.Context, id int, actors []string) error {
+// RestoreMovie brings back a deleted movie together with its cast. Restoring
+// a movie that is not deleted does nothing.
+func (repository *MovieRepository) RestoreMovie(ctx context.Context, id int) error {
+
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	defer tx.Rollback()
+
+	var deleted bool
+	err = tx.QueryRowContext(ctx, LockAnyMovie, id).Scan(&deleted)
+	if err == sql.ErrNoRows {
+		return core.NewErrMovieDoesNotExist()
+	}
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if !deleted {
+		return nil
+	}
+
+	before, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
 
-	_, err := repository.Db.ExecContext(ctx, AddActorsToMovie, actors, id)
+	_, err = tx.ExecContext(ctx, RestoreMovie, id)
 
+	var e *pgconn.PgError
 	if err != nil {
-		var e *pgconn.PgError
-		log.Info(err.Error())
 		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			detail := e.Detail
-			var missingID int
-			var missingType string
-			if strings.Contains(detail, "actor_id") {
-				missingType = "actor_id"
-			} else if strings.Contains(detail, "movie_id") {
-				missingType = "movie_id"
-			}
-			_, err := fmt.Sscanf(detail, "Key (%s)=(%d)", &missingType, &missingID)
-			log.Info(err.Error())
-			return fmt.Errorf(fmt.Sprintf("Missing ID: %d, Type: %s\n", missingID, missingType))
+			return core.NewErrMovieAlreadyExists()
 		}
-		return fmt.Errorf("Internal server error")
+		return dbError(ctx, err)
+	}
+
+	_, err = tx.ExecContext(ctx, TouchMovieActors, id)
+
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	after, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, id, core.AuditRestore, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
 	}
 
 	return nil
 }
 
-func (repository *MovieRepository) DeleteActors(ctx context.Context, id int, actors []string) error {
-	_, err := repository.Db.ExecContext(ctx, DeleteActorsFromMovie, actors, id)
+// PurgeMovies removes movies deleted before the given time for good and
+// returns how many were removed.
+func (repository *MovieRepository) PurgeMovies(ctx context.Context, before time.Time) (int, error) {
 
+	tx, err := begin(ctx, repository.Db)
 	if err != nil {
-		var e *pgconn.PgError
-		log.Info(err.Error())
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			detail := e.Detail
-			var missingID int
-			var missingType string
-			if strings.Contains(detail, "actor_id") {
-				missingType = "actor_id"
-			} else if strings.Contains(detail, "movie_id") {
-				missingType = "movie_id"
-			}
-			_, err := fmt.Sscanf(detail, "Key (%s)=(%d)", &missingType, &missingID)
-			log.Info(err.Error())
-			return fmt.Errorf(fmt.Sprintf("Missing ID: %d, Type: %s\n", missingID, missingType))
-		}
-		return fmt.Errorf("Internal server error")
+		return 0, dbError(ctx, err)
 	}
 
-	return nil
+	defer tx.Rollback()
+
+	ids, err := queryIDs(ctx, tx, ExpiredMovies, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err = purge(ctx, tx, core.AuditMovie, ids, PurgeMovieLinks, PurgeMovies); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, dbError(ctx, err)
+	}
+
+	return len(ids), nil
 }
 
-func (repository *MovieRepository) GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error) {
+// UpdateMovie sets a single column, comparing the stored version with
+// version first unless it is 0.
+func (repository *MovieRepository) UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	column, ok := movieColumns[columnName]
+	if !ok {
+		return core.NewErrUnknownColumn()
+	}
 
-	var movies []*core.Movie
+	tx, err := begin(ctx, repository.Db)
+	if err != nil {
+		return dbError(ctx, err)
+	}
 
-	rows, err := repository.Db.QueryContext(ctx, SortMoviesByRating)
-	if err == sql.ErrNoRows {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+	defer tx.Rollback()
+
+	if err = lockRow(ctx, tx, LockMovie, id, core.NewErrMovieDoesNotExist()); err != nil {
+		return err
 	}
+
+	before, err := movieSnapshot(ctx, tx, id)
 	if err != nil {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return err
 	}
 
-	for rows.Next() {
-		movie := &core.Movie{}
-		err = rows.Scan(&movie.Id, &movie.Title, &movie.Descr, &movie.Release, &movie.Rating, &movie.Actors)
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(UpdateMovie, column), newValue, id, version)
 
-		if err != nil {
-			log.Info(err.Error())
-			return nil, fmt.Errorf("Internal server error")
+	var e *pgconn.PgError
+	if err != nil {
+		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+			return core.NewErrMovieAlreadyExists()
 		}
-		movies = append(movies, movie)
+		return dbError(ctx, err)
 	}
 
-	return movies, nil
-}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return dbError(ctx, err)
+	}
 
-func (repository *MovieRepository) GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error) {
-	var movies []*core.Movie
+	if rows == 0 {
+		logging.From(ctx).Debug("no rows affected")
+		return versionError(ctx, tx, MovieVersion, id, core.NewErrMovieDoesNotExist())
+	}
 
-	rows, err := repository.Db.QueryContext(ctx, SortMoviesByTitle)
-	if err == sql.ErrNoRows {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+	after, err := movieSnapshot(ctx, tx, id)
+	if err != nil {
+		return err
 	}
+
+	if err = writeAudit(ctx, tx, core.AuditMovie, id, core.AuditUpdate, before, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+
+}
+
+// GetMovie loads a movie together with its cast in one query.
+func (repository *MovieRepository) GetMovie(ctx context.Context, id int) (*core.MovieDetail, error) {
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, GetMovie, id)
 	if err != nil {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 
+	defer rows.Close()
+
+	var movie *core.MovieDetail
+	versions := []string{}
+
 	for rows.Next() {
-		movie := &core.Movie{}
-		err = rows.Scan(&movie.Id, &movie.Title, &movie.Descr, &movie.Release, &movie.Rating, &movie.Actors)
+		detail := &core.MovieDetail{}
+		var actorID, billing sql.NullInt64
+		var name, sex, bd, character, castVersion sql.NullString
+
+		err = rows.Scan(&detail.Id, &detail.Title, &detail.Descr, &detail.Release, &detail.Rating, &detail.Version,
+			&actorID, &name, &sex, &bd, &character, &billing, &castVersion)
 
 		if err != nil {
-			log.Info(err.Error())
-			return nil, fmt.Errorf("Internal server error")
+			return nil, dbError(ctx, err)
 		}
-		movies = append(movies, movie)
+
+		if movie == nil {
+			movie = detail
+			movie.Actors = []*core.CastActor{}
+		}
+
+		if !actorID.Valid {
+			continue
+		}
+
+		actor := &core.CastActor{
+			Id:        int(actorID.Int64),
+			Name:      name.String,
+			Bd:        bd.String,
+			Character: character.String,
+			Billing:   int(billing.Int64),
+		}
+		actor.Sex, _ = utf8.DecodeRuneInString(sex.String)
+		movie.Actors = append(movie.Actors, actor)
+		versions = append(versions, castVersion.String)
 	}
 
-	return movies, nil
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	if movie == nil {
+		return nil, core.NewErrMovieDoesNotExist()
+	}
 
+	movie.CastVersion = strings.Join(versions, ",")
+
+	return movie, nil
 }
 
-func (repository *MovieRepository) GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error) {
-	var movies []*core.Movie
+func (repository *MovieRepository) GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error) {
 
-	rows, err := repository.Db.QueryContext(ctx, SortMoviesByReleaseDate)
+	rows, err := conn(ctx, reader(ctx, repository.Db, repository.Replicas)).QueryContext(ctx, SortMoviesByRating)
 	if err == sql.ErrNoRows {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 	if err != nil {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 
-	for rows.Next() {
-		movie := &core.Movie{}
-		err = rows.Scan(&movie.Id, &movie.Title, &movie.Descr, &movie.Release, &movie.Rating, &movie.Actors)
+	return scanMovies(ctx, rows)
+}
 
-		if err != nil {
-			log.Info(err.Error())
-			return nil, fmt.Errorf("Internal server error")
-		}
-		movies = append(movies, movie)
+func (repository *MovieRepository) GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error) {
+	rows, err := conn(ctx, reader(ctx, repository.Db, repository.Replicas)).QueryContext(ctx, SortMoviesByTitle)
+	if err == sql.ErrNoRows {
+		return nil, dbError(ctx, err)
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
 	}
 
-	return movies, nil
+	return scanMovies(ctx, rows)
 
 }
 
-func (repository *MovieRepository) SearchMovie(ctx context.Context, search string) ([]*core.Movie, error) {
-	var movies []*core.Movie
+func (repository *MovieRepository) GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error) {
+	rows, err := conn(ctx, reader(ctx, repository.Db, repository.Replicas)).QueryContext(ctx, SortMoviesByReleaseDate)
+	if err == sql.ErrNoRows {
+		return nil, dbError(ctx, err)
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return scanMovies(ctx, rows)
+
+}
 
-	rows, err := repository.Db.QueryContext(ctx, SearchMovie, search)
+func (repository *MovieRepository) SearchMovie(ctx context.Context, search string) ([]*core.Movie, error) {
+	rows, err := conn(ctx, reader(ctx, repository.Db, repository.Replicas)).QueryContext(ctx, SearchMovie, search)
 	if err == sql.ErrNoRows {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
+	}
+	if err != nil {
+		return nil, dbError(ctx, err)
 	}
+
+	return scanMovies(ctx, rows)
+}
+
+// movieSnapshot loads a movie as recorded in the audit log.
+func movieSnapshot(ctx context.Context, tx queryer, id int) (*core.Movie, error) {
+
+	rows, err := tx.QueryContext(ctx, MovieSnapshot, id)
 	if err != nil {
-		log.Info(err.Error())
-		return nil, fmt.Errorf("Internal server error")
+		return nil, dbError(ctx, err)
 	}
 
+	movies, err := scanMovies(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(movies) == 0 {
+		return nil, core.NewErrMovieDoesNotExist()
+	}
+
+	return movies[0], nil
+}
+
+func scanMovies(ctx context.Context, rows *tracedRows) ([]*core.Movie, error) {
+	defer rows.Close()
+
+	var movies []*core.Movie
+	typeMap := pgtype.NewMap()
+
 	for rows.Next() {
 		movie := &core.Movie{}
-		err = rows.Scan(&movie.Id, &movie.Title, &movie.Descr, &movie.Release, &movie.Rating, &movie.Actors)
+		err := rows.Scan(&movie.Id, &movie.Title, &movie.Descr, &movie.Release, &movie.Rating, typeMap.SQLScanner(&movie.Actors))
 
 		if err != nil {
-			log.Info(err.Error())
-			return nil, fmt.Errorf("Internal server error")
+			return nil, dbError(ctx, err)
 		}
 		movies = append(movies, movie)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
 	return movies, nil
 }
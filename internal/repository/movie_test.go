@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/pgtest"
+)
+
+func TestMovieRepository(t *testing.T) {
+
+	db := pgtest.Start(t)
+	movies := NewMovieRepository(db, nil)
+	actors := NewActorRepository(db, nil)
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		first := createActor(t, actors, "Benedict Cumberbatch")
+		second := createActor(t, actors, "Martin Freeman")
+
+		movie := &core.Movie{Title: "Sherlock", Descr: "A detective", Release: "2010-07-25", Rating: 9, Actors: []int{second, first, second}}
+		if err := movies.CreateMovie(ctx, movie); err != nil {
+			t.Fatal(err)
+		}
+		if movie.Id != 1 {
+			t.Errorf("id = %d, want 1", movie.Id)
+		}
+		if !reflect.DeepEqual(movie.Actors, []int{second, first}) {
+			t.Errorf("actors = %v, want duplicates dropped", movie.Actors)
+		}
+
+		detail, err := movies.GetMovie(ctx, movie.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Title != "Sherlock" || detail.Descr != "A detective" || detail.Release != "2010-07-25" || detail.Rating != 9 {
+			t.Errorf("movie = %+v", detail)
+		}
+		if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{first, second}) {
+			t.Errorf("cast = %v, want %v", got, []int{first, second})
+		}
+		if detail.Actors[0].Name != "Benedict Cumberbatch" || detail.Actors[0].Sex != 'M' || detail.Actors[0].Bd != "1976-07-19" {
+			t.Errorf("cast actor = %+v", detail.Actors[0])
+		}
+	})
+
+	t.Run("create errors", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+
+		tests := []struct {
+			name  string
+			movie *core.Movie
+			want  *core.MyError
+		}{
+			{"duplicate title", &core.Movie{Title: "Sherlock", Descr: "d", Release: "2010-07-25", Rating: 1}, core.NewErrMovieAlreadyExists()},
+			{"missing actors", &core.Movie{Title: "Elementary", Descr: "d", Release: "2012-09-27", Rating: 7, Actors: []int{9, actor, 7}}, core.NewErrActorsDoNotExist([]int{7, 9})},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				assertError(t, movies.CreateMovie(ctx, test.movie), test.want)
+			})
+		}
+
+		list, err := movies.GetAllMoviesByTitle(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 {
+			t.Errorf("%d movies stored, failed creates must leave nothing behind", len(list))
+		}
+	})
+
+	t.Run("get missing", func(t *testing.T) {
+		pgtest.Reset(t, db)
+
+		_, err := movies.GetMovie(ctx, 42)
+		assertError(t, err, core.NewErrMovieDoesNotExist())
+	})
+
+	t.Run("update", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		id := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+		createMovie(t, movies, "Elementary", 7, "2012-09-27")
+
+		for column, value := range map[string]interface{}{"title": "Sherlock Holmes", "descr": "Baker Street", "release": "2010-07-26", "rating": 10} {
+			if err := movies.UpdateMovie(ctx, id, 0, column, value); err != nil {
+				t.Fatalf("updating %s: %v", column, err)
+			}
+		}
+
+		detail, err := movies.GetMovie(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if detail.Title != "Sherlock Holmes" || detail.Descr != "Baker Street" || detail.Release != "2010-07-26" || detail.Rating != 10 {
+			t.Errorf("movie = %+v", detail)
+		}
+
+		if err := movies.UpdateMovie(ctx, id, detail.Version, "rating", 8); err != nil {
+			t.Fatalf("updating at the current version: %v", err)
+		}
+
+		tests := []struct {
+			name    string
+			id      int
+			version int
+			column  string
+			value   interface{}
+			want    *core.MyError
+		}{
+			{"stale version", id, detail.Version, "rating", 7, core.NewErrVersionMismatch()},
+			{"duplicate title", id, 0, "title", "Elementary", core.NewErrMovieAlreadyExists()},
+			{"unknown column", id, 0, "id", 5, core.NewErrUnknownColumn()},
+			{"missing movie", 42, 0, "rating", 7, core.NewErrMovieDoesNotExist()},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				assertError(t, movies.UpdateMovie(ctx, test.id, test.version, test.column, test.value), test.want)
+			})
+		}
+	})
+
+	// DeleteMovie used to delete from the wrong table, the actor sharing
+	// the id of the movie went instead.
+	t.Run("delete keeps the actor with the same id", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		id := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+		if id != actor {
+			t.Fatalf("movie %d and actor %d must share the id", id, actor)
+		}
+
+		if err := movies.DeleteMovie(ctx, id, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := movies.GetMovie(ctx, id)
+		assertError(t, err, core.NewErrMovieDoesNotExist())
+
+		stored, err := actors.GetActor(ctx, actor)
+		if err != nil {
+			t.Fatalf("actor of the deleted movie: %v", err)
+		}
+		if len(stored.Movies) != 0 {
+			t.Errorf("actor movies = %v, want the deleted movie left out", stored.Movies)
+		}
+
+		assertError(t, movies.DeleteMovie(ctx, id, 0), core.NewErrMovieDoesNotExist())
+	})
+
+	t.Run("delete at a stale version", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		id := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+
+		detail, err := movies.GetMovie(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertError(t, movies.DeleteMovie(ctx, id, detail.Version+1), core.NewErrVersionMismatch())
+		if err := movies.DeleteMovie(ctx, id, detail.Version); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("restore", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		id := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+
+		if err := movies.RestoreMovie(ctx, id); err != nil {
+			t.Fatalf("restoring a live movie: %v", err)
+		}
+		if err := movies.DeleteMovie(ctx, id, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		// The title is free while the movie is deleted.
+		other := createMovie(t, movies, "Sherlock", 5, "2011-01-01")
+		assertError(t, movies.RestoreMovie(ctx, id), core.NewErrMovieAlreadyExists())
+
+		if err := movies.DeleteMovie(ctx, other, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := movies.RestoreMovie(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+
+		detail, err := movies.GetMovie(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := detailActorIDs(detail); !reflect.DeepEqual(got, []int{actor}) {
+			t.Errorf("cast = %v, want it restored with the movie", got)
+		}
+
+		assertError(t, movies.RestoreMovie(ctx, 42), core.NewErrMovieDoesNotExist())
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		id := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+		createMovie(t, movies, "Elementary", 7, "2012-09-27", actor)
+
+		if err := movies.DeleteMovie(ctx, id, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		purged, err := movies.PurgeMovies(ctx, time.Now().Add(-time.Hour))
+		if err != nil || purged != 0 {
+			t.Fatalf("purging before the deletion = %d, %v, want 0", purged, err)
+		}
+
+		purged, err = movies.PurgeMovies(ctx, time.Now().Add(time.Hour))
+		if err != nil || purged != 1 {
+			t.Fatalf("purged = %d, %v, want 1", purged, err)
+		}
+
+		assertError(t, movies.RestoreMovie(ctx, id), core.NewErrMovieDoesNotExist())
+
+		stored, err := actors.GetActor(ctx, actor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(stored.Movies, []int{2}) {
+			t.Errorf("actor movies = %v, want [2]", stored.Movies)
+		}
+	})
+
+	t.Run("list and search", func(t *testing.T) {
+		pgtest.Reset(t, db)
+		actor := createActor(t, actors, "Benedict Cumberbatch")
+		sherlock := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+		elementary := createMovie(t, movies, "Elementary", 7, "2012-09-27")
+		doctor := createMovie(t, movies, "Doctor Strange", 8, "2016-10-20", actor)
+		deleted := createMovie(t, movies, "Deleted", 10, "2020-01-01")
+
+		if err := movies.DeleteMovie(ctx, deleted, 0); err != nil {
+			t.Fatal(err)
+		}
+
+		// Searches are not ordered.
+		tests := []struct {
+			name      string
+			list      func(context.Context) ([]*core.Movie, error)
+			want      []int
+			unordered bool
+		}{
+			{"by rating", movies.GetAllMoviesByRating, []int{sherlock, doctor, elementary}, false},
+			{"by title", movies.GetAllMoviesByTitle, []int{doctor, elementary, sherlock}, false},
+			{"by release date", movies.GetAllMoviesByReleaseDate, []int{doctor, elementary, sherlock}, false},
+			{"search by title", searchMovies(movies, "sher"), []int{sherlock}, true},
+			{"search by actor", searchMovies(movies, "cumber"), []int{sherlock, doctor}, true},
+			{"search without match", searchMovies(movies, "deleted"), nil, true},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				list, err := test.list(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := movieIDs(list)
+				if test.unordered {
+					sort.Ints(got)
+				}
+				if !reflect.DeepEqual(got, test.want) {
+					t.Errorf("movies = %v, want %v", got, test.want)
+				}
+			})
+		}
+	})
+}
+
+// assertError fails unless err is want, down to the ids and names it
+// lists.
+func assertError(t *testing.T, err error, want *core.MyError) {
+	t.Helper()
+
+	var got *core.MyError
+	if !errors.As(err, &got) {
+		t.Fatalf("error = %v, want %s", err, want.Type)
+	}
+	if got.Type != want.Type || !reflect.DeepEqual(got.Inf.Ids, want.Inf.Ids) || !reflect.DeepEqual(got.Inf.Names, want.Inf.Names) {
+		t.Fatalf("error = %s %v %v, want %s %v %v", got.Type, got.Inf.Ids, got.Inf.Names, want.Type, want.Inf.Ids, want.Inf.Names)
+	}
+}
+
+func createActor(t *testing.T, actors *ActorRepository, name string) int {
+	t.Helper()
+
+	actor := &core.Actor{Name: name, Sex: 'M', Bd: "1976-07-19"}
+	if err := actors.CreateActor(context.Background(), actor); err != nil {
+		t.Fatal(err)
+	}
+	return actor.Id
+}
+
+func createMovie(t *testing.T, movies *MovieRepository, title string, rating int, release string, actors ...int) int {
+	t.Helper()
+
+	movie := &core.Movie{Title: title, Descr: "About " + title, Release: release, Rating: rating, Actors: actors}
+	if err := movies.CreateMovie(context.Background(), movie); err != nil {
+		t.Fatal(err)
+	}
+	return movie.Id
+}
+
+func searchMovies(movies *MovieRepository, search string) func(context.Context) ([]*core.Movie, error) {
+	return func(ctx context.Context) ([]*core.Movie, error) {
+		return movies.SearchMovie(ctx, search)
+	}
+}
+
+func movieIDs(movies []*core.Movie) []int {
+	var ids []int
+	for _, movie := range movies {
+		ids = append(ids, movie.Id)
+	}
+	return ids
+}
+
+func detailActorIDs(movie *core.MovieDetail) []int {
+	var ids []int
+	for _, actor := range movie.Actors {
+		ids = append(ids, actor.Id)
+	}
+	return ids
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+// Replicas spreads the list and search queries over read replicas in turn.
+// Reads of a user who committed a write within stickiness go to the
+// primary, so that the user sees it despite replication lag, and so do
+// reads shared by every user after anyone wrote. Anonymous users count as
+// one. Replicas failing their health check are skipped until they pass,
+// and with none healthy every read goes to the primary.
+type Replicas struct {
+	primary    *sqlx.DB
+	replicas   []*replica
+	stickiness time.Duration
+	next       atomic.Uint64
+
+	mu        sync.Mutex
+	writes    map[string]time.Time
+	lastWrite time.Time
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// NewReplicas routes reads to replicas once Run found them healthy.
+func NewReplicas(primary *sqlx.DB, replicas []*sqlx.DB, stickiness time.Duration) *Replicas {
+	routed := make([]*replica, 0, len(replicas))
+	for _, db := range replicas {
+		routed = append(routed, &replica{db: db})
+	}
+	return &Replicas{primary: primary, replicas: routed, stickiness: stickiness, writes: map[string]time.Time{}}
+}
+
+// Wrote records that the user of ctx committed a write. It is meant to run
+// as a service.OnCommit hook.
+func (replicas *Replicas) Wrote(ctx context.Context) {
+	now := time.Now()
+
+	replicas.mu.Lock()
+	defer replicas.mu.Unlock()
+
+	for user, at := range replicas.writes {
+		if now.Sub(at) >= replicas.stickiness {
+			delete(replicas.writes, user)
+		}
+	}
+	replicas.writes[core.UserFrom(ctx)] = now
+	replicas.lastWrite = now
+}
+
+// DB returns the database the reads made with ctx go to.
+func (replicas *Replicas) DB(ctx context.Context) *sqlx.DB {
+	if replicas.sticky(ctx) {
+		return replicas.primary
+	}
+
+	start := replicas.next.Add(1)
+	for i := range replicas.replicas {
+		replica := replicas.replicas[(start+uint64(i))%uint64(len(replicas.replicas))]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+	return replicas.primary
+}
+
+func (replicas *Replicas) sticky(ctx context.Context) bool {
+	replicas.mu.Lock()
+	defer replicas.mu.Unlock()
+
+	at := replicas.writes[core.UserFrom(ctx)]
+	if core.IsSharedRead(ctx) {
+		at = replicas.lastWrite
+	}
+	return time.Since(at) < replicas.stickiness
+}
+
+// Run checks the health of every replica each interval, each check
+// bounded by timeout, until ctx is done.
+func (replicas *Replicas) Run(ctx context.Context, interval time.Duration, timeout time.Duration) {
+	ctx = logging.WithFields(ctx, log.Fields{"job": "replicas"})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for i, replica := range replicas.replicas {
+			replica.check(logging.WithFields(ctx, log.Fields{"replica": i}), timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (replica *replica) check(ctx context.Context, timeout time.Duration) {
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := replica.db.PingContext(pingCtx)
+	healthy := err == nil
+
+	if replica.healthy.Swap(healthy) == healthy {
+		return
+	}
+	if healthy {
+		logging.From(ctx).Info("replica healthy, routing reads to it")
+	} else {
+		logging.From(ctx).WithError(err).Warn("replica unhealthy, routing its reads elsewhere")
+	}
+}
+
+// reader returns the database for the read-only queries made with ctx:
+// a replica when there are any, db otherwise.
+func reader(ctx context.Context, db *sqlx.DB, replicas *Replicas) *sqlx.DB {
+	if replicas == nil {
+		return db
+	}
+	return replicas.DB(ctx)
+}
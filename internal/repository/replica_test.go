@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReplicas(t *testing.T) {
+
+	primary, first, second := &sqlx.DB{}, &sqlx.DB{}, &sqlx.DB{}
+	replicas := NewReplicas(primary, []*sqlx.DB{first, second}, time.Minute)
+	alice := core.WithUser(context.Background(), "apikey:alice")
+	bob := core.WithUser(context.Background(), "apikey:bob")
+
+	if db := replicas.DB(alice); db != primary {
+		t.Fatal("read went to a replica not yet found healthy")
+	}
+
+	for _, replica := range replicas.replicas {
+		replica.healthy.Store(true)
+	}
+	if a, b := replicas.DB(alice), replicas.DB(alice); a == b || a == primary || b == primary {
+		t.Error("reads were not spread over the replicas in turn")
+	}
+
+	replicas.replicas[0].healthy.Store(false)
+	for i := 0; i < 3; i++ {
+		if db := replicas.DB(alice); db != second {
+			t.Fatal("read went to an unhealthy replica")
+		}
+	}
+
+	// Only the writer and shared reads stick to the primary.
+	replicas.Wrote(alice)
+	if db := replicas.DB(alice); db != primary {
+		t.Error("read of the writer went to a replica")
+	}
+	if db := replicas.DB(bob); db != second {
+		t.Error("read of another user went to the primary")
+	}
+	if db := replicas.DB(core.WithSharedRead(bob)); db != primary {
+		t.Error("shared read went to a replica after a write")
+	}
+
+	var none *Replicas
+	if db := reader(alice, primary, none); db != primary {
+		t.Error("read without replicas did not go to the primary")
+	}
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"filmoteka/internal/tracing"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// statements names the queries of the package in spans. Queries missing
+// here are named by their first keyword.
+var statements = map[string]string{
+	CreateActor:             "CreateActor",
+	UpdateActor:             "UpdateActor",
+	LockActor:               "LockActor",
+	ActorVersion:            "ActorVersion",
+	TouchActorMovies:        "TouchActorMovies",
+	DeleteActor:             "DeleteActor",
+	LockAnyActor:            "LockAnyActor",
+	RestoreActor:            "RestoreActor",
+	ExpiredActors:           "ExpiredActors",
+	PurgeActorLinks:         "PurgeActorLinks",
+	PurgeActors:             "PurgeActors",
+	ActorSnapshot:           "ActorSnapshot",
+	GetActor:                "GetActor",
+	GetAllActors:            "GetAllActors",
+	CreateAPIKey:            "CreateAPIKey",
+	ListAPIKeys:             "ListAPIKeys",
+	RevokeAPIKey:            "RevokeAPIKey",
+	FindAPIKey:              "FindAPIKey",
+	TouchAPIKey:             "TouchAPIKey",
+	LockAudit:               "LockAudit",
+	InsertAudit:             "InsertAudit",
+	ListAudit:               "ListAudit",
+	DeleteExpiredKeys:       "DeleteExpiredKeys",
+	ReserveKey:              "ReserveKey",
+	GetKey:                  "GetKey",
+	CompleteKey:             "CompleteKey",
+	ReleaseKey:              "ReleaseKey",
+	CreateMovie:             "CreateMovie",
+	UpdateMovie:             "UpdateMovie",
+	MovieVersion:            "MovieVersion",
+	AddActorsToMovie:        "AddActorsToMovie",
+	DeleteActorsFromMovie:   "DeleteActorsFromMovie",
+	LockMovie:               "LockMovie",
+	TouchMovie:              "TouchMovie",
+	TouchMovieActors:        "TouchMovieActors",
+	MissingActors:           "MissingActors",
+	LinkedActors:            "LinkedActors",
+	ActorsByName:            "ActorsByName",
+	DeleteMovie:             "DeleteMovie",
+	LockAnyMovie:            "LockAnyMovie",
+	RestoreMovie:            "RestoreMovie",
+	ExpiredMovies:           "ExpiredMovies",
+	PurgeMovieLinks:         "PurgeMovieLinks",
+	PurgeMovies:             "PurgeMovies",
+	GetMovie:                "GetMovie",
+	MovieSnapshot:           "MovieSnapshot",
+	SortMoviesByRating:      "SortMoviesByRating",
+	SortMoviesByReleaseDate: "SortMoviesByReleaseDate",
+	SortMoviesByTitle:       "SortMoviesByTitle",
+	SearchMovie:             "SearchMovie",
+	TouchActors:             "TouchActors",
+	CreateWebhook:           "CreateWebhook",
+	ListWebhooks:            "ListWebhooks",
+	DeleteWebhook:           "DeleteWebhook",
+	WebhookExists:           "WebhookExists",
+	QueueDelivery:           "QueueDelivery",
+	ListDeliveries:          "ListDeliveries",
+	RetryDelivery:           "RetryDelivery",
+	DeliveryExists:          "DeliveryExists",
+	ClaimDeliveries:         "ClaimDeliveries",
+	CompleteDelivery:        "CompleteDelivery",
+}
+
+// sqlQueryer is implemented by *sqlx.DB and *sql.Tx.
+type sqlQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// traced runs every query in a span carrying the name of the statement and
+// the number of rows it affected or returned.
+type traced struct {
+	q sqlQueryer
+}
+
+func (t traced) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+
+	res, err := t.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.rows_affected", n))
+	}
+	return res, nil
+}
+
+// QueryContext leaves the span open until the rows are closed or read to
+// the end.
+func (t traced) QueryContext(ctx context.Context, query string, args ...interface{}) (*tracedRows, error) {
+	ctx, span := startQuery(ctx, query)
+
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		tracing.Error(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+// QueryRowContext ends the span once the query ran, before the row is
+// scanned.
+func (t traced) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+
+	row := t.q.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != nil {
+		tracing.Error(span, err)
+	}
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := statementName(query)
+	return tracing.Start(ctx, "sql "+name,
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.statement.name", name),
+		attribute.String("db.query.text", query),
+	)
+}
+
+// statementName finds the name of query, also of queries formatted from a
+// statement such as UpdateMovie.
+func statementName(query string) string {
+	if name, ok := statements[query]; ok {
+		return name
+	}
+	for statement, name := range statements {
+		if prefix, _, ok := strings.Cut(statement, "%s"); ok && strings.HasPrefix(query, prefix) {
+			return name
+		}
+	}
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	return strings.ToUpper(keyword)
+}
+
+// tracedRows counts the rows read and ends the span of their query.
+type tracedRows struct {
+	*sql.Rows
+	span  trace.Span
+	count int
+	ended bool
+}
+
+func (rows *tracedRows) Next() bool {
+	if rows.Rows.Next() {
+		rows.count++
+		return true
+	}
+	rows.end()
+	return false
+}
+
+func (rows *tracedRows) Close() error {
+	err := rows.Rows.Close()
+	rows.end()
+	return err
+}
+
+func (rows *tracedRows) end() {
+	if rows.ended {
+		return
+	}
+	rows.ended = true
+	if err := rows.Rows.Err(); err != nil {
+		tracing.Error(rows.span, err)
+	}
+	rows.span.SetAttributes(attribute.Int("db.response.returned_rows", rows.count))
+	rows.span.End()
+}
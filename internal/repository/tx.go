@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"filmoteka/internal/logging"
+	"filmoteka/internal/tracing"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// txAttempts is how often Transactor runs a unit of work that keeps
+	// failing on serialization failures or deadlocks.
+	txAttempts = 3
+	txBackoff  = 20 * time.Millisecond
+)
+
+// queryer is implemented by traced and txScope, so helpers can run inside or
+// outside a transaction.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*tracedRows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// txScope is the transaction of a single repository method. When the method
+// runs inside Transactor.InTransaction it joins the outer transaction and
+// leaves committing or rolling it back to its owner.
+type txScope struct {
+	traced
+	tx     *sql.Tx
+	joined bool
+}
+
+func (tx *txScope) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.tx.Commit()
+}
+
+func (tx *txScope) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.tx.Rollback()
+}
+
+// begin joins the transaction carried by ctx or starts a new one.
+func begin(ctx context.Context, db *sqlx.DB) (*txScope, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return &txScope{traced: traced{tx}, tx: tx, joined: true}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &txScope{traced: traced{tx}, tx: tx}, nil
+}
+
+// retryableError is a database error after which the whole transaction can
+// succeed when run again.
+type retryableError struct {
+	err error
+}
+
+func (err *retryableError) Error() string {
+	return "Internal server error"
+}
+
+func (err *retryableError) Unwrap() error {
+	return err.err
+}
+
+// dbError logs a database error with the logger of the request and hides it
+// from clients. Serialization failures and deadlocks are kept recognisable
+// so that Transactor can retry them.
+func dbError(ctx context.Context, err error) error {
+	var e *pgconn.PgError
+	if errors.As(err, &e) && (e.Code == pgerrcode.SerializationFailure || e.Code == pgerrcode.DeadlockDetected) {
+		logging.From(ctx).WithError(err).Warn("database conflict")
+		return &retryableError{err: err}
+	}
+	logging.From(ctx).WithError(err).Error("database error")
+	return fmt.Errorf("Internal server error")
+}
+
+// conn returns the transaction carried by ctx, so reads made within a unit
+// of work see its uncommitted changes, or db otherwise.
+func conn(ctx context.Context, db *sqlx.DB) queryer {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return traced{tx}
+	}
+	return traced{db}
+}
+
+type Transactor struct {
+	Db *sqlx.DB
+}
+
+func NewTransactor(db *sqlx.DB) *Transactor {
+	return &Transactor{Db: db}
+}
+
+// InTransaction runs fn in a single transaction shared by every repository
+// call made with the context passed to fn. The transaction is rolled back
+// when fn returns an error and run again from the start, up to txAttempts
+// times, when it failed on a serialization failure or a deadlock. Nested
+// calls join the outer transaction, which alone retries.
+func (transactor *Transactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := transactor.run(ctx, attempt, fn)
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt == txAttempts {
+			return err
+		}
+
+		logging.From(ctx).WithError(retryable.err).Warnf("retrying transaction, attempt %d failed", attempt)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt) * txBackoff):
+		}
+	}
+}
+
+func (transactor *Transactor) run(ctx context.Context, attempt int, fn func(ctx context.Context) error) error {
+
+	ctx, span := tracing.Start(ctx, "sql transaction", attribute.Int("db.transaction.attempt", attempt))
+	defer span.End()
+
+	tx, err := transactor.Db.BeginTx(ctx, nil)
+	if err != nil {
+		return tracing.Error(span, dbError(ctx, err))
+	}
+
+	defer tx.Rollback()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return tracing.Error(span, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return tracing.Error(span, dbError(ctx, err))
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/pgtest"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTransactor(t *testing.T) {
+
+	db := pgtest.Start(t)
+	transactor := NewTransactor(db)
+	actors := NewActorRepository(db, nil)
+	ctx := context.Background()
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		pgtest.Reset(t, db)
+
+		attempts := 0
+		err := transactor.InTransaction(ctx, func(ctx context.Context) error {
+			attempts++
+			if err := actors.CreateActor(ctx, &core.Actor{Name: "Benedict Cumberbatch", Sex: 'M', Bd: "1976-07-19"}); err != nil {
+				return err
+			}
+			if attempts < txAttempts {
+				return dbError(ctx, &pgconn.PgError{Code: pgerrcode.SerializationFailure})
+			}
+			return nil
+		})
+		if err != nil || attempts != txAttempts {
+			t.Fatalf("InTransaction = %v after %d attempts, want success after %d", err, attempts, txAttempts)
+		}
+
+		// Only the last attempt was committed.
+		all, err := actors.GetAllActors(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 1 {
+			t.Errorf("actors = %+v, want one", all)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		pgtest.Reset(t, db)
+
+		attempts := 0
+		err := transactor.InTransaction(ctx, func(ctx context.Context) error {
+			attempts++
+			return dbError(ctx, &pgconn.PgError{Code: pgerrcode.DeadlockDetected})
+		})
+		if err == nil || attempts != txAttempts {
+			t.Errorf("InTransaction = %v after %d attempts, want an error after %d", err, attempts, txAttempts)
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		pgtest.Reset(t, db)
+
+		attempts := 0
+		err := transactor.InTransaction(ctx, func(ctx context.Context) error {
+			attempts++
+			return core.NewErrMovieDoesNotExist()
+		})
+		assertError(t, err, core.NewErrMovieDoesNotExist())
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+	})
+}
+
+func TestDBError(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: pgerrcode.SerializationFailure}, true},
+		{"deadlock", &pgconn.PgError{Code: pgerrcode.DeadlockDetected}, true},
+		{"unique violation", &pgconn.PgError{Code: pgerrcode.UniqueViolation}, false},
+		{"not a postgres error", errors.New("connection reset"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := dbError(context.Background(), test.err)
+
+			var retryable *retryableError
+			if errors.As(err, &retryable) != test.retryable {
+				t.Errorf("dbError(%v) retryable = %v, want %v", test.err, !test.retryable, test.retryable)
+			}
+			if err.Error() != "Internal server error" {
+				t.Errorf("error = %q, want the database error hidden", err)
+			}
+		})
+	}
+}
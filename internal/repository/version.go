@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"filmoteka/internal/core"
+)
+
+// TouchActors bumps the version of actors whose movie links changed.
+const TouchActors = "UPDATE Actors SET version = version + 1, updated_at = now() WHERE id = ANY($1);"
+
+// versionError explains why a versioned statement affected no rows: either
+// the row is gone or its version moved on.
+func versionError(ctx context.Context, db queryer, query string, id int, notFound *core.MyError) error {
+	var version int
+	err := db.QueryRowContext(ctx, query, id).Scan(&version)
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return core.NewErrVersionMismatch()
+}
+
+// lockRow locks a row for the rest of the transaction so that snapshots
+// taken for the audit log stay accurate.
+func lockRow(ctx context.Context, tx queryer, query string, id int, notFound *core.MyError) error {
+	var locked int
+	err := tx.QueryRowContext(ctx, query, id).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"filmoteka/internal/core"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jmoiron/sqlx"
+)
+
+type WebhookRepository struct {
+	Db *sqlx.DB
+}
+
+const (
+	CreateWebhook  = "INSERT INTO Webhooks(url, events, secret) VALUES ($1, $2, $3) RETURNING id, created_at;"
+	ListWebhooks   = "SELECT id, url, events, created_at FROM Webhooks ORDER BY id;"
+	DeleteWebhook  = "DELETE FROM Webhooks WHERE id = $1;"
+	WebhookExists  = "SELECT EXISTS(SELECT 1 FROM Webhooks WHERE id = $1);"
+	QueueDelivery  = "INSERT INTO WebhookDeliveries(webhook_id, event_id, event_type, payload) SELECT id, $1, $2, $3 FROM Webhooks WHERE $2 = ANY(events);"
+	ListDeliveries = `SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, created_at
+	FROM WebhookDeliveries WHERE webhook_id = $1 AND ($2 = '' OR status = $2) ORDER BY id DESC LIMIT $3;`
+	RetryDelivery = `UPDATE WebhookDeliveries SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''
+	WHERE id = $1 AND webhook_id = $2 AND status = 'dead';`
+	DeliveryExists = "SELECT EXISTS(SELECT 1 FROM WebhookDeliveries WHERE id = $1 AND webhook_id = $2);"
+	// ClaimDeliveries leases due deliveries to one dispatcher until $2, after
+	// which another one may pick them up if the first did not complete them.
+	ClaimDeliveries = `WITH claimed AS (
+		UPDATE WebhookDeliveries SET next_attempt_at = $2 WHERE id IN (
+			SELECT id FROM WebhookDeliveries WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING id, webhook_id, event_id, event_type, payload, attempts)
+	SELECT c.id, c.webhook_id, c.event_id, c.event_type, c.payload, c.attempts, w.url, w.secret
+	FROM claimed c JOIN Webhooks w ON w.id = c.webhook_id ORDER BY c.id;`
+	CompleteDelivery = "UPDATE WebhookDeliveries SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5 WHERE id = $1;"
+)
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{Db: db}
+}
+
+func (repository *WebhookRepository) CreateWebhook(ctx context.Context, webhook *core.Webhook) error {
+
+	err := conn(ctx, repository.Db).QueryRowContext(ctx, CreateWebhook, webhook.URL, webhook.Events, webhook.Secret).Scan(&webhook.Id, &webhook.CreatedAt)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+// ListWebhooks returns every webhook without its secret.
+func (repository *WebhookRepository) ListWebhooks(ctx context.Context) ([]*core.Webhook, error) {
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, ListWebhooks)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	typeMap := pgtype.NewMap()
+	webhooks := []*core.Webhook{}
+	for rows.Next() {
+		webhook := &core.Webhook{}
+		if err := rows.Scan(&webhook.Id, &webhook.URL, typeMap.SQLScanner(&webhook.Events), &webhook.CreatedAt); err != nil {
+			return nil, dbError(ctx, err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook together with its deliveries.
+func (repository *WebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+
+	res, err := conn(ctx, repository.Db).ExecContext(ctx, DeleteWebhook, id)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return core.NewErrWebhookDoesNotExist()
+	}
+
+	return nil
+}
+
+// ListDeliveries returns up to limit deliveries of a webhook, newest first.
+// An empty status matches every delivery.
+func (repository *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]*core.WebhookDelivery, error) {
+
+	if err := repository.exists(ctx, WebhookExists, core.NewErrWebhookDoesNotExist(), webhookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, ListDeliveries, webhookID, status, limit)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	deliveries := []*core.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &core.WebhookDelivery{}
+		var payload []byte
+		err := rows.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &payload,
+			&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastError, &delivery.CreatedAt)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		delivery.Payload = payload
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return deliveries, nil
+}
+
+// RetryDelivery queues a dead delivery again with a fresh attempt budget.
+func (repository *WebhookRepository) RetryDelivery(ctx context.Context, webhookID int, id int64) error {
+
+	res, err := conn(ctx, repository.Db).ExecContext(ctx, RetryDelivery, id, webhookID)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	if err := repository.exists(ctx, DeliveryExists, core.NewErrDeliveryDoesNotExist(), id, webhookID); err != nil {
+		return err
+	}
+
+	return core.NewErrDeliveryNotDead()
+}
+
+// ClaimDeliveries returns up to limit due deliveries and hides them from
+// other dispatchers until lease.
+func (repository *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Time) ([]*core.WebhookDelivery, error) {
+
+	rows, err := conn(ctx, repository.Db).QueryContext(ctx, ClaimDeliveries, limit, lease)
+	if err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	defer rows.Close()
+
+	var deliveries []*core.WebhookDelivery
+	for rows.Next() {
+		delivery := &core.WebhookDelivery{Status: core.DeliveryPending}
+		var payload []byte
+		err := rows.Scan(&delivery.Id, &delivery.WebhookId, &delivery.EventId, &delivery.EventType, &payload,
+			&delivery.Attempts, &delivery.URL, &delivery.Secret)
+		if err != nil {
+			return nil, dbError(ctx, err)
+		}
+		delivery.Payload = payload
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, dbError(ctx, err)
+	}
+
+	return deliveries, nil
+}
+
+// CompleteDelivery stores the outcome of an attempt.
+func (repository *WebhookRepository) CompleteDelivery(ctx context.Context, delivery *core.WebhookDelivery) error {
+
+	_, err := conn(ctx, repository.Db).ExecContext(ctx, CompleteDelivery, delivery.Id, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
+
+func (repository *WebhookRepository) exists(ctx context.Context, query string, notFound *core.MyError, args ...interface{}) error {
+	var exists bool
+	if err := conn(ctx, repository.Db).QueryRowContext(ctx, query, args...).Scan(&exists); err != nil {
+		return dbError(ctx, err)
+	}
+	if !exists {
+		return notFound
+	}
+	return nil
+}
+
+// queueDeliveries writes the event an audit entry publishes to the outbox
+// of every webhook subscribed to it, in the transaction of the change.
+func queueDeliveries(ctx context.Context, tx queryer, entry *core.AuditEntry) error {
+
+	event := core.EventFromAudit(entry)
+	if event == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return dbError(ctx, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, QueueDelivery, event.Id, event.Name(), string(payload)); err != nil {
+		return dbError(ctx, err)
+	}
+
+	return nil
+}
@@ -3,35 +3,97 @@ package service
 import (
 	"context"
 	"filmoteka/internal/core"
+	"filmoteka/internal/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ActorRepository interface {
 	CreateActor(ctx context.Context, actor *core.Actor) error
-	UpdateActor(ctx context.Context, id int, columnName string, newValue interface{}) error
-	DeleteActor(ctx context.Context, id int) error
+	UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	DeleteActor(ctx context.Context, id int, version int) error
+	RestoreActor(ctx context.Context, id int) error
+	PurgeActors(ctx context.Context, before time.Time) (int, error)
+	GetActor(ctx context.Context, id int) (*core.Actor, error)
 	GetAllActors(ctx context.Context) ([]*core.Actor, error)
 }
 
 type ActorService struct {
 	actorRepository ActorRepository
+	transactor      Transactor
 }
 
-func NewActorService(actorRepository ActorRepository) *ActorService {
-	return &ActorService{actorRepository: actorRepository}
+// NewActorService runs every change through transactor, which retries it
+// when it conflicts with a concurrent one.
+func NewActorService(actorRepository ActorRepository, transactor Transactor) *ActorService {
+	return &ActorService{actorRepository: actorRepository, transactor: transactor}
 }
 
 func (service *ActorService) CreateActor(ctx context.Context, actor *core.Actor) error {
-	return service.actorRepository.CreateActor(ctx, actor)
+	ctx, span := startSpan(ctx, "ActorService.CreateActor")
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.actorRepository.CreateActor(ctx, actor)
+	}))
+}
+
+func (service *ActorService) UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	ctx, span := startSpan(ctx, "ActorService.UpdateActor", attribute.Int("actor.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.actorRepository.UpdateActor(ctx, id, version, columnName, newValue)
+	}))
+}
+
+func (service *ActorService) DeleteActor(ctx context.Context, id int, version int) error {
+	ctx, span := startSpan(ctx, "ActorService.DeleteActor", attribute.Int("actor.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.actorRepository.DeleteActor(ctx, id, version)
+	}))
 }
 
-func (service *ActorService) UpdateActor(ctx context.Context, id int, columnName string, newValue interface{}) error {
-	return service.actorRepository.UpdateActor(ctx, id, columnName, newValue)
+func (service *ActorService) RestoreActor(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "ActorService.RestoreActor", attribute.Int("actor.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.actorRepository.RestoreActor(ctx, id)
+	}))
 }
 
-func (service *ActorService) DeleteActor(ctx context.Context, id int) error {
-	return service.actorRepository.DeleteActor(ctx, id)
+// PurgeActors removes actors deleted before the given time for good.
+func (service *ActorService) PurgeActors(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := startSpan(ctx, "ActorService.PurgeActors")
+	defer span.End()
+
+	var purged int
+	err := service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = service.actorRepository.PurgeActors(ctx, before)
+		return err
+	})
+	span.SetAttributes(attribute.Int("purged", purged))
+	return purged, tracing.Error(span, err)
+}
+
+func (service *ActorService) GetActor(ctx context.Context, id int) (*core.Actor, error) {
+	ctx, span := startSpan(ctx, "ActorService.GetActor", attribute.Int("actor.id", id))
+	defer span.End()
+
+	actor, err := service.actorRepository.GetActor(ctx, id)
+	return actor, tracing.Error(span, err)
 }
 
 func (service *ActorService) GetAllActors(ctx context.Context) ([]*core.Actor, error) {
-	return service.actorRepository.GetAllActors(ctx)
+	ctx, span := startSpan(ctx, "ActorService.GetAllActors")
+	defer span.End()
+
+	actors, err := service.actorRepository.GetAllActors(ctx)
+	span.SetAttributes(attribute.Int("actors", len(actors)))
+	return actors, tracing.Error(span, err)
 }
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+)
+
+func TestActorServiceCreate(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		actor *core.Actor
+		want  *core.MyError
+	}{
+		{"new actor", &core.Actor{Name: "Martin Freeman", Sex: 'M', Bd: "1971-09-08"}, nil},
+		{"duplicate name", &core.Actor{Name: "Benedict Cumberbatch", Sex: 'M', Bd: "1976-07-19"}, core.NewErrActorAlreadyExists()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, actors := newTestServices()
+			ctx := context.Background()
+			createActor(t, actors, "Benedict Cumberbatch")
+
+			err := actors.CreateActor(ctx, test.actor)
+			if test.want != nil {
+				assertError(t, err, test.want)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := actors.GetActor(ctx, test.actor.Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Name != test.actor.Name || stored.Sex != test.actor.Sex || stored.Bd != test.actor.Bd {
+				t.Errorf("actor = %+v, want %+v", stored, test.actor)
+			}
+		})
+	}
+}
+
+func TestActorServiceUpdate(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		id      int
+		version int
+		column  string
+		value   interface{}
+		want    *core.MyError
+	}{
+		{"name", 1, 0, "name", "Benedict Timothy Carlton Cumberbatch", nil},
+		{"bd at the current version", 1, 1, "bd", "1976-07-20", nil},
+		{"stale version", 1, 7, "bd", "1976-07-20", core.NewErrVersionMismatch()},
+		{"duplicate name", 1, 0, "name", "Martin Freeman", core.NewErrActorAlreadyExists()},
+		{"unknown column", 1, 0, "movies", "1", core.NewErrUnknownColumn()},
+		{"missing actor", 42, 0, "bd", "1976-07-20", core.NewErrActorDoesNotExist()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, actors := newTestServices()
+			createActor(t, actors, "Benedict Cumberbatch")
+			createActor(t, actors, "Martin Freeman")
+
+			err := actors.UpdateActor(context.Background(), test.id, test.version, test.column, test.value)
+			if test.want != nil {
+				assertError(t, err, test.want)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestActorServiceDeleteAndRestore(t *testing.T) {
+
+	_, actors := newTestServices()
+	ctx := context.Background()
+	id := createActor(t, actors, "Benedict Cumberbatch")
+
+	assertError(t, actors.DeleteActor(ctx, 42, 0), core.NewErrActorDoesNotExist())
+	assertError(t, actors.DeleteActor(ctx, id, 7), core.NewErrVersionMismatch())
+
+	if err := actors.DeleteActor(ctx, id, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err := actors.GetActor(ctx, id)
+	assertError(t, err, core.NewErrActorDoesNotExist())
+
+	// The name is free while the actor is deleted.
+	other := createActor(t, actors, "Benedict Cumberbatch")
+	assertError(t, actors.RestoreActor(ctx, id), core.NewErrActorAlreadyExists())
+
+	if err := actors.DeleteActor(ctx, other, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := actors.RestoreActor(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := actors.GetActor(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if purged, err := actors.PurgeActors(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+		t.Fatalf("purged = %d, %v, want the other actor", purged, err)
+	}
+	assertError(t, actors.RestoreActor(ctx, other), core.NewErrActorDoesNotExist())
+	assertError(t, actors.RestoreActor(ctx, 42), core.NewErrActorDoesNotExist())
+}
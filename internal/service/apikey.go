@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix starts every key, so that leaked keys are easy to find.
+	apiKeyPrefix = "fk_"
+	// apiKeyShown is how much of a key is kept in the clear to tell keys
+	// apart in listings.
+	apiKeyShown = len(apiKeyPrefix) + 8
+
+	// touchInterval bounds how often the last use of a key is written.
+	touchInterval = time.Minute
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *core.APIKey, hash string) error
+	ListAPIKeys(ctx context.Context) ([]*core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	FindAPIKey(ctx context.Context, hash string) (*core.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int, at time.Time) error
+}
+
+type APIKeyService struct {
+	apiKeyRepository APIKeyRepository
+}
+
+func NewAPIKeyService(apiKeyRepository APIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepository: apiKeyRepository}
+}
+
+// IssueAPIKey generates a key with the given name, scopes and expiry. The
+// key itself is returned only here, only its hash is stored.
+func (service *APIKeyService) IssueAPIKey(ctx context.Context, key *core.APIKey) error {
+	if strings.TrimSpace(key.Name) == "" || len(key.Scopes) == 0 {
+		return core.NewErrBadRequest()
+	}
+	for _, scope := range key.Scopes {
+		if !slices.Contains(core.Scopes, scope) {
+			return core.NewErrUnknownScope(scope)
+		}
+	}
+	slices.Sort(key.Scopes)
+	key.Scopes = slices.Compact(key.Scopes)
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		return core.NewErrBadRequest()
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		logging.From(ctx).WithError(err).Error("could not generate API key")
+		return core.NewErrInternal()
+	}
+	raw := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key.Prefix = raw[:apiKeyShown]
+	key.LastUsedAt = nil
+	if err := service.apiKeyRepository.CreateAPIKey(ctx, key, hashAPIKey(raw)); err != nil {
+		return err
+	}
+
+	key.Key = raw
+	return nil
+}
+
+func (service *APIKeyService) ListAPIKeys(ctx context.Context) ([]*core.APIKey, error) {
+	return service.apiKeyRepository.ListAPIKeys(ctx)
+}
+
+func (service *APIKeyService) RevokeAPIKey(ctx context.Context, id int) error {
+	return service.apiKeyRepository.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate returns the key a request presented. Unknown, revoked and
+// expired keys are rejected alike.
+func (service *APIKeyService) Authenticate(ctx context.Context, raw string) (*core.APIKey, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, core.NewErrUnauthorized()
+	}
+
+	key, err := service.apiKeyRepository.FindAPIKey(ctx, hashAPIKey(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if key == nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, core.NewErrUnauthorized()
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		// A lost update only makes the last use older, it must not fail
+		// the request.
+		if err := service.apiKeyRepository.TouchAPIKey(ctx, key.Id, now); err != nil {
+			logging.From(ctx).WithError(err).WithField("api_key_id", key.Id).Warn("could not record API key use")
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
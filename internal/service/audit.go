@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/core"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+type AuditRepository interface {
+	ListAudit(ctx context.Context, filter core.AuditFilter) ([]*core.AuditEntry, error)
+}
+
+type AuditService struct {
+	auditRepository AuditRepository
+}
+
+func NewAuditService(auditRepository AuditRepository) *AuditService {
+	return &AuditService{auditRepository: auditRepository}
+}
+
+// ListAudit returns a page of the audit log, oldest entries first.
+func (service *AuditService) ListAudit(ctx context.Context, filter core.AuditFilter) (*core.AuditPage, error) {
+	switch filter.Entity {
+	case core.AuditMovie, core.AuditActor, "":
+	default:
+		return nil, core.NewErrUnknownEntity(filter.Entity)
+	}
+
+	if filter.EntityId < 0 || filter.After < 0 || filter.Limit < 0 || (filter.EntityId != 0 && filter.Entity == "") {
+		return nil, core.NewErrBadRequest()
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	filter.Limit = min(filter.Limit, maxAuditLimit)
+
+	// One extra entry tells whether there is a next page.
+	limit := filter.Limit
+	filter.Limit++
+
+	entries, err := service.auditRepository.ListAudit(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &core.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.Next = page.Entries[limit-1].Id
+	}
+	if page.Entries == nil {
+		page.Entries = []*core.AuditEntry{}
+	}
+
+	return page, nil
+}
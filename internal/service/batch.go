@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"filmoteka/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const maxBatchOperations = 100
+
+// BatchService applies lists of operations atomically.
+type BatchService struct {
+	movieService *MovieService
+	actorService *ActorService
+	transactor   Transactor
+}
+
+func NewBatchService(movieService *MovieService, actorService *ActorService, transactor Transactor) *BatchService {
+	return &BatchService{movieService: movieService, actorService: actorService, transactor: transactor}
+}
+
+// batchRow is a row created earlier in a batch.
+type batchRow struct {
+	entity string
+	id     int
+}
+
+// batchRefs maps the refs defined so far in a batch to their rows.
+type batchRefs map[string]batchRow
+
+// resolve returns the id ref stands for. A ref of the wrong entity is
+// treated as undefined.
+func (refs batchRefs) resolve(ref core.BatchRef, entity string) (int, error) {
+	if ref.Ref == "" {
+		if ref.Id <= 0 {
+			return 0, core.NewErrBadRequest()
+		}
+		return ref.Id, nil
+	}
+
+	row, ok := refs[ref.Ref]
+	if !ok || row.entity != entity {
+		return 0, core.NewErrUnknownRef(ref.Ref)
+	}
+	return row.id, nil
+}
+
+func (refs batchRefs) cast(members []core.BatchCastMember) ([]core.CastMember, error) {
+	cast := make([]core.CastMember, 0, len(members))
+	for _, member := range members {
+		resolved := core.CastMember{ActorName: member.ActorName, Character: member.Character, Billing: member.Billing}
+		if member.ActorId != (core.BatchRef{}) {
+			id, err := refs.resolve(member.ActorId, core.AuditActor)
+			if err != nil {
+				return nil, err
+			}
+			resolved.ActorId = id
+		}
+		cast = append(cast, resolved)
+	}
+	return cast, nil
+}
+
+// Run applies operations in order within one transaction and reports the
+// row each of them applied to. When an operation fails nothing is applied
+// and the returned *core.BatchError says which operation it was.
+func (service *BatchService) Run(ctx context.Context, operations []core.BatchOperation) ([]core.BatchResult, error) {
+	if len(operations) == 0 || len(operations) > maxBatchOperations {
+		return nil, core.NewErrBatchTooLarge(maxBatchOperations)
+	}
+
+	ctx, span := startSpan(ctx, "BatchService.Run", attribute.Int("operations", len(operations)))
+	defer span.End()
+
+	var results []core.BatchResult
+	err := service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		results = make([]core.BatchResult, 0, len(operations))
+		refs := batchRefs{}
+
+		for i, operation := range operations {
+			id, err := service.apply(ctx, refs, operation)
+			if err != nil {
+				return &core.BatchError{Index: i, Err: err}
+			}
+			results = append(results, core.BatchResult{Op: operation.Op, Ref: operation.Ref, Id: id})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, tracing.Error(span, err)
+	}
+
+	return results, nil
+}
+
+func (service *BatchService) apply(ctx context.Context, refs batchRefs, operation core.BatchOperation) (int, error) {
+	if operation.Ref != "" {
+		if operation.Op != core.BatchCreateMovie && operation.Op != core.BatchCreateActor {
+			return 0, core.NewErrBadRequest()
+		}
+		if _, ok := refs[operation.Ref]; ok {
+			return 0, core.NewErrDuplicateRef(operation.Ref)
+		}
+	}
+
+	switch operation.Op {
+	case core.BatchCreateMovie:
+		if operation.Movie == nil {
+			return 0, core.NewErrBadRequest()
+		}
+		movie := *operation.Movie
+		if err := service.movieService.CreateMovie(ctx, &movie); err != nil {
+			return 0, err
+		}
+		if operation.Ref != "" {
+			refs[operation.Ref] = batchRow{entity: core.AuditMovie, id: movie.Id}
+		}
+		return movie.Id, nil
+
+	case core.BatchCreateActor:
+		if operation.Actor == nil {
+			return 0, core.NewErrBadRequest()
+		}
+		actor := *operation.Actor
+		if err := service.actorService.CreateActor(ctx, &actor); err != nil {
+			return 0, err
+		}
+		if operation.Ref != "" {
+			refs[operation.Ref] = batchRow{entity: core.AuditActor, id: actor.Id}
+		}
+		return actor.Id, nil
+
+	case core.BatchUpdateMovie, core.BatchDeleteMovie, core.BatchLink, core.BatchUnlink:
+		id, err := refs.resolve(operation.Id, core.AuditMovie)
+		if err != nil {
+			return 0, err
+		}
+		return id, service.applyToMovie(ctx, refs, id, operation)
+
+	case core.BatchUpdateActor:
+		id, err := refs.resolve(operation.Id, core.AuditActor)
+		if err != nil {
+			return 0, err
+		}
+		return id, service.actorService.UpdateActor(ctx, id, operation.Version, operation.Column, operation.Value)
+
+	case core.BatchDeleteActor:
+		id, err := refs.resolve(operation.Id, core.AuditActor)
+		if err != nil {
+			return 0, err
+		}
+		return id, service.actorService.DeleteActor(ctx, id, operation.Version)
+	}
+
+	return 0, core.NewErrUnknownOperation(operation.Op)
+}
+
+func (service *BatchService) applyToMovie(ctx context.Context, refs batchRefs, id int, operation core.BatchOperation) error {
+	switch operation.Op {
+	case core.BatchUpdateMovie:
+		return service.movieService.UpdateMovie(ctx, id, operation.Version, operation.Column, operation.Value)
+	case core.BatchDeleteMovie:
+		return service.movieService.DeleteMovie(ctx, id, operation.Version)
+	}
+
+	cast, err := refs.cast(operation.Actors)
+	if err != nil {
+		return err
+	}
+
+	if operation.Op == core.BatchLink {
+		return service.movieService.AddActors(ctx, id, cast)
+	}
+	return service.movieService.DeleteActors(ctx, id, cast)
+}
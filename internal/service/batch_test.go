@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+)
+
+func TestBatchService(t *testing.T) {
+
+	store := memory.NewStore()
+	transactor := memory.NewTransactor(store)
+	movies := NewMovieService(memory.NewMovieRepository(store), transactor)
+	actors := NewActorService(memory.NewActorRepository(store), transactor)
+	batches := NewBatchService(movies, actors, transactor)
+	ctx := context.Background()
+
+	sherlock := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+
+	results, err := batches.Run(ctx, []core.BatchOperation{
+		{Op: core.BatchCreateActor, Ref: "andrew", Actor: &core.Actor{Name: "Andrew Scott", Sex: 'M', Bd: "1976-10-21"}},
+		{Op: core.BatchLink, Id: core.BatchRef{Id: sherlock}, Actors: []core.BatchCastMember{{ActorId: core.BatchRef{Ref: "andrew"}, Character: "Jim Moriarty"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	andrew := results[0].Id
+	if got := movieCast(t, movies, sherlock); !reflect.DeepEqual(got, []int{andrew}) {
+		t.Errorf("cast = %v, want the actor created in the batch", got)
+	}
+
+	// A failing operation rolls back the ones before it.
+	tests := []struct {
+		name       string
+		operations []core.BatchOperation
+		index      int
+		want       *core.MyError
+	}{
+		{"missing movie", []core.BatchOperation{
+			{Op: core.BatchCreateActor, Actor: &core.Actor{Name: "Martin Freeman", Sex: 'M', Bd: "1971-09-08"}},
+			{Op: core.BatchUpdateMovie, Id: core.BatchRef{Id: 42}, Column: "rating", Value: 5},
+		}, 1, core.NewErrMovieDoesNotExist()},
+		{"ref of another entity", []core.BatchOperation{
+			{Op: core.BatchCreateActor, Ref: "martin", Actor: &core.Actor{Name: "Martin Freeman", Sex: 'M', Bd: "1971-09-08"}},
+			{Op: core.BatchDeleteMovie, Id: core.BatchRef{Ref: "martin"}},
+		}, 1, core.NewErrUnknownRef("martin")},
+		{"unknown operation", []core.BatchOperation{
+			{Op: core.BatchDeleteActor, Id: core.BatchRef{Id: andrew}},
+			{Op: "rename"},
+		}, 1, core.NewErrUnknownOperation("rename")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := batches.Run(ctx, test.operations)
+
+			var batchErr *core.BatchError
+			if !errors.As(err, &batchErr) || batchErr.Index != test.index {
+				t.Fatalf("error = %v, want operation %d to fail", err, test.index)
+			}
+			assertError(t, err, test.want)
+
+			all, err := actors.GetAllActors(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 1 || all[0].Id != andrew {
+				t.Errorf("actors = %+v, want only Andrew Scott", all)
+			}
+		})
+	}
+
+	_, err = batches.Run(ctx, nil)
+	assertError(t, err, core.NewErrBatchTooLarge(maxBatchOperations))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"sync"
+)
+
+// EventService publishes the audit log as a feed of changes. Subscribers
+// are woken up after every committed unit of work and read new events from
+// the log, so they see changes in order and without gaps.
+type EventService struct {
+	auditRepository AuditRepository
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+func NewEventService(auditRepository AuditRepository) *EventService {
+	return &EventService{auditRepository: auditRepository, subscribers: map[chan struct{}]struct{}{}}
+}
+
+// Subscribe returns a channel that receives a value after changes were
+// committed and a function that ends the subscription.
+func (service *EventService) Subscribe() (<-chan struct{}, func()) {
+	wake := make(chan struct{}, 1)
+
+	service.mu.Lock()
+	service.subscribers[wake] = struct{}{}
+	service.mu.Unlock()
+
+	return wake, func() {
+		service.mu.Lock()
+		delete(service.subscribers, wake)
+		service.mu.Unlock()
+	}
+}
+
+// Notify wakes up every subscriber. Subscribers that were not done with
+// the previous notification get a single one for both.
+func (service *EventService) Notify() {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	for wake := range service.subscribers {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Events returns up to limit events after the given id, of one entity or of
+// both when entity is empty, and the id to continue from.
+func (service *EventService) Events(ctx context.Context, after int64, entity string, limit int) ([]*core.Event, int64, error) {
+	switch entity {
+	case core.AuditMovie, core.AuditActor, "":
+	default:
+		return nil, after, core.NewErrUnknownEntity(entity)
+	}
+
+	entries, err := service.auditRepository.ListAudit(ctx, core.AuditFilter{Entity: entity, After: after, Limit: limit})
+	if err != nil {
+		return nil, after, err
+	}
+
+	events := make([]*core.Event, 0, len(entries))
+	for _, entry := range entries {
+		if event := core.EventFromAudit(entry); event != nil {
+			events = append(events, event)
+		}
+		after = entry.Id
+	}
+
+	return events, after, nil
+}
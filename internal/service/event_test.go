@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+)
+
+func TestEventServiceNotify(t *testing.T) {
+
+	store := memory.NewStore()
+	events := NewEventService(memory.NewAuditRepository(store))
+	actors := NewActorService(memory.NewActorRepository(store), OnCommit(memory.NewTransactor(store), func(context.Context) { events.Notify() }))
+	ctx := context.Background()
+
+	wake, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	createActor(t, actors, "Benedict Cumberbatch")
+	select {
+	case <-wake:
+	default:
+		t.Fatal("subscriber not woken after a commit")
+	}
+
+	err := actors.CreateActor(ctx, &core.Actor{Name: "Benedict Cumberbatch", Sex: 'M', Bd: "1976-07-19"})
+	assertError(t, err, core.NewErrActorAlreadyExists())
+	select {
+	case <-wake:
+		t.Fatal("subscriber woken after a failed change")
+	default:
+	}
+
+	got, next, err := events.Events(ctx, 0, core.AuditActor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Type != core.EventCreated || next != got[0].Id {
+		t.Errorf("events = %+v up to %d, want the actor created", got, next)
+	}
+
+	_, _, err = events.Events(ctx, 0, "director", 10)
+	assertError(t, err, core.NewErrUnknownEntity("director"))
+}
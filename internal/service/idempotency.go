@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"net/http"
+	"time"
+)
+
+// idempotencyLock bounds how long a key stays claimed by a request that
+// never finishes, e.g. because the server crashed.
+const idempotencyLock = time.Minute
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, client, key, hash string, expires time.Time) (*core.StoredResponse, error)
+	Complete(ctx context.Context, client, key string, response *core.StoredResponse, expires time.Time) error
+	Release(ctx context.Context, client, key string) error
+}
+
+type IdempotencyService struct {
+	idempotencyRepository IdempotencyRepository
+	ttl                   time.Duration
+}
+
+func NewIdempotencyService(idempotencyRepository IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{idempotencyRepository: idempotencyRepository, ttl: ttl}
+}
+
+// Begin claims the key of client for a request identified by hash. Keys of
+// different clients are unrelated. A non-nil response means the request was
+// already answered and must be replayed.
+func (service *IdempotencyService) Begin(ctx context.Context, client, key, hash string) (*core.StoredResponse, error) {
+	return service.idempotencyRepository.Reserve(ctx, client, key, hash, time.Now().Add(idempotencyLock))
+}
+
+// Finish stores the response for the TTL. Server errors and rejected
+// credentials are not stored, the key is released so that the request can
+// be retried, once the credentials are fixed for the latter.
+func (service *IdempotencyService) Finish(ctx context.Context, client, key string, response *core.StoredResponse) error {
+	switch {
+	case response.StatusCode >= http.StatusInternalServerError,
+		response.StatusCode == http.StatusUnauthorized,
+		response.StatusCode == http.StatusForbidden:
+		return service.idempotencyRepository.Release(ctx, client, key)
+	}
+	return service.idempotencyRepository.Complete(ctx, client, key, response, time.Now().Add(service.ttl))
+}
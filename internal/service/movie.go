@@ -3,15 +3,21 @@ package service
 import (
 	"context"
 	"filmoteka/internal/core"
-	"fmt"
+	"filmoteka/internal/tracing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type MovieRepository interface {
 	CreateMovie(ctx context.Context, movie *core.Movie) error
-	DeleteMovie() error
-	UpdateMovie(ctx context.Context, id int, columnName string, newValue interface{}) error
-	AddActors(ctx context.Context, id int, actors []string) error
-	DeleteActors(ctx context.Context, id int, actors []string) error
+	DeleteMovie(ctx context.Context, id int, version int) error
+	RestoreMovie(ctx context.Context, id int) error
+	PurgeMovies(ctx context.Context, before time.Time) (int, error)
+	UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	AddActors(ctx context.Context, id int, cast []core.CastMember) error
+	DeleteActors(ctx context.Context, id int, cast []core.CastMember) error
+	GetMovie(ctx context.Context, id int) (*core.MovieDetail, error)
 	GetAllMoviesByRating(ctx context.Context) ([]*core.Movie, error)
 	GetAllMoviesByTitle(ctx context.Context) ([]*core.Movie, error)
 	GetAllMoviesByReleaseDate(ctx context.Context) ([]*core.Movie, error)
@@ -20,44 +26,117 @@ type MovieRepository interface {
 
 type MovieService struct {
 	movieRepository MovieRepository
+	transactor      Transactor
 }
 
-func NewMovieService(movieRepository MovieRepository) *MovieService {
-	return &MovieService{movieRepository: movieRepository}
+// NewMovieService runs every change through transactor, which retries it
+// when it conflicts with a concurrent one.
+func NewMovieService(movieRepository MovieRepository, transactor Transactor) *MovieService {
+	return &MovieService{movieRepository: movieRepository, transactor: transactor}
 }
 
 func (service *MovieService) CreateMovie(ctx context.Context, movie *core.Movie) error {
-	return service.movieRepository.CreateMovie(ctx, movie)
+	ctx, span := startSpan(ctx, "MovieService.CreateMovie")
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.CreateMovie(ctx, movie)
+	}))
 }
 
-func (service *MovieService) DeleteMovie() error {
-	return service.movieRepository.DeleteMovie()
+func (service *MovieService) DeleteMovie(ctx context.Context, id int, version int) error {
+	ctx, span := startSpan(ctx, "MovieService.DeleteMovie", attribute.Int("movie.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.DeleteMovie(ctx, id, version)
+	}))
 }
 
-func (service *MovieService) UpdateMovie(ctx context.Context, id int, columnName string, newValue interface{}) error {
-	return service.movieRepository.UpdateMovie(ctx, id, columnName, newValue)
+func (service *MovieService) RestoreMovie(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "MovieService.RestoreMovie", attribute.Int("movie.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.RestoreMovie(ctx, id)
+	}))
 }
 
-func (service *MovieService) AddActors(ctx context.Context, id int, actors []string) error {
-	return service.movieRepository.AddActors(ctx, id, actors)
+// PurgeMovies removes movies deleted before the given time for good.
+func (service *MovieService) PurgeMovies(ctx context.Context, before time.Time) (int, error) {
+	ctx, span := startSpan(ctx, "MovieService.PurgeMovies")
+	defer span.End()
+
+	var purged int
+	err := service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = service.movieRepository.PurgeMovies(ctx, before)
+		return err
+	})
+	span.SetAttributes(attribute.Int("purged", purged))
+	return purged, tracing.Error(span, err)
 }
 
-func (service *MovieService) DeleteActors(ctx context.Context, id int, actors []string) error {
-	return service.movieRepository.DeleteActors(ctx, id, actors)
+func (service *MovieService) UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error {
+	ctx, span := startSpan(ctx, "MovieService.UpdateMovie", attribute.Int("movie.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.UpdateMovie(ctx, id, version, columnName, newValue)
+	}))
+}
+
+func (service *MovieService) AddActors(ctx context.Context, id int, cast []core.CastMember) error {
+	ctx, span := startSpan(ctx, "MovieService.AddActors", attribute.Int("movie.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.AddActors(ctx, id, cast)
+	}))
 }
 
-func (service *MovieService) GetAll(ctx context.Context, id int, actors []string, sorting string) ([]*core.Movie, error) {
+func (service *MovieService) DeleteActors(ctx context.Context, id int, cast []core.CastMember) error {
+	ctx, span := startSpan(ctx, "MovieService.DeleteActors", attribute.Int("movie.id", id))
+	defer span.End()
+
+	return tracing.Error(span, service.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return service.movieRepository.DeleteActors(ctx, id, cast)
+	}))
+}
+
+func (service *MovieService) GetMovie(ctx context.Context, id int) (*core.MovieDetail, error) {
+	ctx, span := startSpan(ctx, "MovieService.GetMovie", attribute.Int("movie.id", id))
+	defer span.End()
+
+	movie, err := service.movieRepository.GetMovie(ctx, id)
+	return movie, tracing.Error(span, err)
+}
+
+func (service *MovieService) GetAll(ctx context.Context, sorting string) ([]*core.Movie, error) {
+	ctx, span := startSpan(ctx, "MovieService.GetAll", attribute.String("sort", sorting))
+	defer span.End()
+
+	var movies []*core.Movie
+	var err error
 	switch sorting {
-	case "rating":
-		return service.movieRepository.GetAllMoviesByRating(ctx)
+	case "rating", "":
+		movies, err = service.movieRepository.GetAllMoviesByRating(ctx)
 	case "title":
-		return service.movieRepository.GetAllMoviesByTitle(ctx)
+		movies, err = service.movieRepository.GetAllMoviesByTitle(ctx)
 	case "release":
-		return service.movieRepository.GetAllMoviesByReleaseDate(ctx)
+		movies, err = service.movieRepository.GetAllMoviesByReleaseDate(ctx)
+	default:
+		err = core.NewErrUnknownSorting()
 	}
-	return nil, fmt.Errorf("Internal server error")
+	span.SetAttributes(attribute.Int("movies", len(movies)))
+	return movies, tracing.Error(span, err)
 }
 
 func (service *MovieService) SearchMovie(ctx context.Context, search string) ([]*core.Movie, error) {
-	return service.movieRepository.SearchMovie(ctx, search)
+	ctx, span := startSpan(ctx, "MovieService.SearchMovie")
+	defer span.End()
+
+	movies, err := service.movieRepository.SearchMovie(ctx, search)
+	span.SetAttributes(attribute.Int("movies", len(movies)))
+	return movies, tracing.Error(span, err)
 }
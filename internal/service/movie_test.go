@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+)
+
+// newTestServices returns movie and actor services sharing an in-memory
+// store.
+func newTestServices() (*MovieService, *ActorService) {
+	store := memory.NewStore()
+	transactor := memory.NewTransactor(store)
+	return NewMovieService(memory.NewMovieRepository(store), transactor), NewActorService(memory.NewActorRepository(store), transactor)
+}
+
+func TestMovieServiceCreate(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		movie *core.Movie
+		want  *core.MyError
+	}{
+		{"new movie", &core.Movie{Title: "Elementary", Descr: "d", Release: "2012-09-27", Rating: 7, Actors: []int{1}}, nil},
+		{"movie without cast", &core.Movie{Title: "Elementary", Descr: "d", Release: "2012-09-27", Rating: 7}, nil},
+		{"duplicate title", &core.Movie{Title: "Sherlock", Descr: "d", Release: "2012-09-27", Rating: 7}, core.NewErrMovieAlreadyExists()},
+		{"missing actors", &core.Movie{Title: "Elementary", Descr: "d", Release: "2012-09-27", Rating: 7, Actors: []int{9, 1, 7}}, core.NewErrActorsDoNotExist([]int{7, 9})},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movies, actors := newTestServices()
+			ctx := context.Background()
+			actor := createActor(t, actors, "Benedict Cumberbatch")
+			createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+
+			err := movies.CreateMovie(ctx, test.movie)
+			if test.want != nil {
+				assertError(t, err, test.want)
+
+				list, err := movies.GetAll(ctx, "title")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(list) != 1 {
+					t.Errorf("%d movies stored, a failed create must leave nothing behind", len(list))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := movieCast(t, movies, test.movie.Id); !reflect.DeepEqual(got, test.movie.Actors) {
+				t.Errorf("cast = %v, want %v", got, test.movie.Actors)
+			}
+		})
+	}
+}
+
+func TestMovieServiceGetAll(t *testing.T) {
+
+	movies, _ := newTestServices()
+	sherlock := createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+	elementary := createMovie(t, movies, "Elementary", 7, "2012-09-27")
+	doctor := createMovie(t, movies, "Doctor Strange", 8, "2016-10-20")
+
+	tests := []struct {
+		sorting string
+		want    []int
+		wantErr *core.MyError
+	}{
+		{"", []int{sherlock, doctor, elementary}, nil},
+		{"rating", []int{sherlock, doctor, elementary}, nil},
+		{"title", []int{doctor, elementary, sherlock}, nil},
+		{"release", []int{doctor, elementary, sherlock}, nil},
+		{"popularity", nil, core.NewErrUnknownSorting()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.sorting, func(t *testing.T) {
+			list, err := movies.GetAll(context.Background(), test.sorting)
+			if test.wantErr != nil {
+				assertError(t, err, test.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := movieIDs(list); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("movies = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMovieServiceSearch(t *testing.T) {
+
+	movies, actors := newTestServices()
+	actor := createActor(t, actors, "Benedict Cumberbatch")
+	sherlock := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+	createMovie(t, movies, "Elementary", 7, "2012-09-27")
+	doctor := createMovie(t, movies, "Doctor Strange", 8, "2016-10-20", actor)
+
+	tests := []struct {
+		search string
+		want   []int
+	}{
+		{"SHER", []int{sherlock}},
+		{"cumber", []int{sherlock, doctor}},
+		{"watson", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.search, func(t *testing.T) {
+			list, err := movies.SearchMovie(context.Background(), test.search)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := movieIDs(list)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("movies = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMovieServiceUpdate(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		id      int
+		version int
+		column  string
+		value   interface{}
+		want    *core.MyError
+	}{
+		{"title", 1, 0, "title", "Sherlock Holmes", nil},
+		{"rating at the current version", 1, 1, "rating", 10, nil},
+		{"stale version", 1, 7, "rating", 10, core.NewErrVersionMismatch()},
+		{"duplicate title", 1, 0, "title", "Elementary", core.NewErrMovieAlreadyExists()},
+		{"unknown column", 1, 0, "id", 2, core.NewErrUnknownColumn()},
+		{"missing movie", 42, 0, "rating", 10, core.NewErrMovieDoesNotExist()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movies, _ := newTestServices()
+			createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+			createMovie(t, movies, "Elementary", 7, "2012-09-27")
+
+			err := movies.UpdateMovie(context.Background(), test.id, test.version, test.column, test.value)
+			if test.want != nil {
+				assertError(t, err, test.want)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestMovieServiceCast(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		add      bool
+		movie    int
+		cast     []core.CastMember
+		want     *core.MyError
+		wantCast []int
+	}{
+		{"add by id and name", true, 1, []core.CastMember{{ActorId: 2, Billing: 2}, {ActorName: "Andrew Scott", Billing: 3}}, nil, []int{2, 3, 1}},
+		{"add linked actors", true, 1, []core.CastMember{{ActorId: 2}, {ActorId: 1}}, core.NewErrActorsAlreadyLinked([]int{1}), []int{1}},
+		{"add missing actors", true, 1, []core.CastMember{{ActorId: 9}, {ActorId: 2}, {ActorId: 7}}, core.NewErrActorsDoNotExist([]int{7, 9}), []int{1}},
+		{"add unknown names", true, 1, []core.CastMember{{ActorName: "Nobody"}}, core.NewErrActorNamesDoNotExist([]string{"Nobody"}), []int{1}},
+		{"add to a missing movie", true, 42, []core.CastMember{{ActorId: 2}}, core.NewErrMovieDoesNotExist(), []int{1}},
+		{"add nobody", true, 42, nil, core.NewErrBadRequest(), []int{1}},
+		{"delete", false, 1, []core.CastMember{{ActorName: "Benedict Cumberbatch"}}, nil, nil},
+		{"delete unlinked actors", false, 1, []core.CastMember{{ActorId: 1}, {ActorId: 2}}, core.NewErrActorsNotLinked([]int{2}), []int{1}},
+		{"delete missing actors", false, 1, []core.CastMember{{ActorId: 9}}, core.NewErrActorsDoNotExist([]int{9}), []int{1}},
+		{"delete nobody", false, 1, []core.CastMember{}, core.NewErrBadRequest(), []int{1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movies, actors := newTestServices()
+			ctx := context.Background()
+			benedict := createActor(t, actors, "Benedict Cumberbatch")
+			createActor(t, actors, "Martin Freeman")
+			createActor(t, actors, "Andrew Scott")
+			createMovie(t, movies, "Sherlock", 9, "2010-07-25", benedict)
+
+			change := movies.DeleteActors
+			if test.add {
+				change = movies.AddActors
+			}
+
+			err := change(ctx, test.movie, test.cast)
+			if test.want != nil {
+				assertError(t, err, test.want)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := movieCast(t, movies, 1); !reflect.DeepEqual(got, test.wantCast) {
+				t.Errorf("cast = %v, want %v", got, test.wantCast)
+			}
+		})
+	}
+}
+
+// Deleting a movie or an actor takes it out of the other side's lists,
+// restoring it brings the links back and purging drops them for good.
+func TestCascadeOnDelete(t *testing.T) {
+
+	movies, actors := newTestServices()
+	ctx := context.Background()
+	actor := createActor(t, actors, "Benedict Cumberbatch")
+	movie := createMovie(t, movies, "Sherlock", 9, "2010-07-25", actor)
+
+	if err := actors.DeleteActor(ctx, actor, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := movieCast(t, movies, movie); len(got) != 0 {
+		t.Errorf("cast after deleting the actor = %v, want none", got)
+	}
+
+	if err := actors.RestoreActor(ctx, actor); err != nil {
+		t.Fatal(err)
+	}
+	if got := movieCast(t, movies, movie); !reflect.DeepEqual(got, []int{actor}) {
+		t.Errorf("cast after restoring the actor = %v, want %v", got, []int{actor})
+	}
+
+	if err := movies.DeleteMovie(ctx, movie, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := actorMovies(t, actors, actor); len(got) != 0 {
+		t.Errorf("movies after deleting the movie = %v, want none", got)
+	}
+	_, err := movies.GetMovie(ctx, movie)
+	assertError(t, err, core.NewErrMovieDoesNotExist())
+
+	if err := movies.RestoreMovie(ctx, movie); err != nil {
+		t.Fatal(err)
+	}
+	if got := actorMovies(t, actors, actor); !reflect.DeepEqual(got, []int{movie}) {
+		t.Errorf("movies after restoring the movie = %v, want %v", got, []int{movie})
+	}
+
+	if err := movies.DeleteMovie(ctx, movie, 0); err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := movies.PurgeMovies(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+		t.Fatalf("purged = %d, %v, want 1", purged, err)
+	}
+	assertError(t, movies.RestoreMovie(ctx, movie), core.NewErrMovieDoesNotExist())
+	if got := actorMovies(t, actors, actor); len(got) != 0 {
+		t.Errorf("movies after purging the movie = %v, want none", got)
+	}
+}
+
+// assertError fails unless err is want, down to the ids and names it
+// lists.
+func assertError(t *testing.T, err error, want *core.MyError) {
+	t.Helper()
+
+	var got *core.MyError
+	if !errors.As(err, &got) {
+		t.Fatalf("error = %v, want %s", err, want.Type)
+	}
+	if got.Type != want.Type || !reflect.DeepEqual(got.Inf.Ids, want.Inf.Ids) || !reflect.DeepEqual(got.Inf.Names, want.Inf.Names) {
+		t.Fatalf("error = %s %v %v, want %s %v %v", got.Type, got.Inf.Ids, got.Inf.Names, want.Type, want.Inf.Ids, want.Inf.Names)
+	}
+}
+
+func createActor(t *testing.T, actors *ActorService, name string) int {
+	t.Helper()
+
+	actor := &core.Actor{Name: name, Sex: 'M', Bd: "1976-07-19"}
+	if err := actors.CreateActor(context.Background(), actor); err != nil {
+		t.Fatal(err)
+	}
+	return actor.Id
+}
+
+func createMovie(t *testing.T, movies *MovieService, title string, rating int, release string, actors ...int) int {
+	t.Helper()
+
+	movie := &core.Movie{Title: title, Descr: "About " + title, Release: release, Rating: rating, Actors: actors}
+	if err := movies.CreateMovie(context.Background(), movie); err != nil {
+		t.Fatal(err)
+	}
+	return movie.Id
+}
+
+func movieCast(t *testing.T, movies *MovieService, id int) []int {
+	t.Helper()
+
+	detail, err := movies.GetMovie(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return detailActorIDs(detail)
+}
+
+func actorMovies(t *testing.T, actors *ActorService, id int) []int {
+	t.Helper()
+
+	actor, err := actors.GetActor(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return actor.Movies
+}
+
+func movieIDs(movies []*core.Movie) []int {
+	var ids []int
+	for _, movie := range movies {
+		ids = append(ids, movie.Id)
+	}
+	return ids
+}
+
+func detailActorIDs(movie *core.MovieDetail) []int {
+	var ids []int
+	for _, actor := range movie.Actors {
+		ids = append(ids, actor.Id)
+	}
+	return ids
+}
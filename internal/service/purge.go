@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// PurgeJob periodically removes movies and actors that were deleted longer
+// than the retention period ago, after which they can not be restored.
+type PurgeJob struct {
+	movieService *MovieService
+	actorService *ActorService
+	retention    time.Duration
+	interval     time.Duration
+}
+
+func NewPurgeJob(movieService *MovieService, actorService *ActorService, retention, interval time.Duration) *PurgeJob {
+	return &PurgeJob{movieService: movieService, actorService: actorService, retention: retention, interval: interval}
+}
+
+// Run purges once per interval until ctx is done.
+func (job *PurgeJob) Run(ctx context.Context) {
+	ctx = logging.WithFields(ctx, log.Fields{"job": "purge"})
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Purge(ctx); err != nil {
+			logging.From(ctx).WithError(err).Error("purge failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes everything deleted before the retention period.
+func (job *PurgeJob) Purge(ctx context.Context) error {
+	ctx = core.WithUser(ctx, core.SystemUser)
+	before := time.Now().Add(-job.retention)
+
+	movies, err := job.movieService.PurgeMovies(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	actors, err := job.actorService.PurgeActors(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if movies > 0 || actors > 0 {
+		logging.From(ctx).WithFields(log.Fields{"movies": movies, "actors": actors}).Info("purged deleted rows")
+	}
+
+	return nil
+}
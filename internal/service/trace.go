@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"filmoteka/internal/logging"
+	"filmoteka/internal/tracing"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts the span of a service method. Its attributes, such as
+// the id of the movie it acts on, are added to the logger of ctx as well.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := tracing.Start(ctx, name, attrs...)
+	if len(attrs) == 0 {
+		return ctx, span
+	}
+
+	fields := log.Fields{}
+	for _, attr := range attrs {
+		fields[strings.ReplaceAll(string(attr.Key), ".", "_")] = attr.Value.AsInterface()
+	}
+	return logging.WithFields(ctx, fields), span
+}
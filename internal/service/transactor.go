@@ -0,0 +1,44 @@
+package service
+
+import "context"
+
+// Transactor runs fn as a unit of work: every repository call made with the
+// context passed to fn takes part in the same transaction, which commits
+// when fn returns nil and rolls back otherwise. fn may run more than once
+// when the transaction conflicts with another one, so it must not have side
+// effects outside the repositories.
+type Transactor interface {
+	InTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OnCommit wraps next so that fn runs after every outermost unit of work
+// that committed. fn gets the context the unit of work was started with,
+// without its cancellation since the change already happened.
+func OnCommit(next Transactor, fn func(ctx context.Context)) Transactor {
+	return &commitHook{next: next, fn: fn}
+}
+
+type commitHook struct {
+	next Transactor
+	fn   func(ctx context.Context)
+}
+
+// commitHookKey marks the units of work a hook already watches, the key of
+// every hook is distinct so that hooks can be stacked.
+type commitHookKey struct {
+	hook *commitHook
+}
+
+func (hook *commitHook) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	key := commitHookKey{hook: hook}
+	if ctx.Value(key) != nil {
+		return hook.next.InTransaction(ctx, fn)
+	}
+
+	if err := hook.next.InTransaction(context.WithValue(ctx, key, true), fn); err != nil {
+		return err
+	}
+
+	hook.fn(context.WithoutCancel(ctx))
+	return nil
+}
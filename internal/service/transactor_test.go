@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+)
+
+func TestOnCommit(t *testing.T) {
+
+	commits := 0
+	transactor := OnCommit(memory.NewTransactor(memory.NewStore()), func(context.Context) { commits++ })
+	ctx := context.Background()
+
+	// Nested units of work commit with the outermost one.
+	err := transactor.InTransaction(ctx, func(ctx context.Context) error {
+		return transactor.InTransaction(ctx, func(ctx context.Context) error { return nil })
+	})
+	if err != nil || commits != 1 {
+		t.Fatalf("InTransaction = %v with %d hook calls, want 1", err, commits)
+	}
+
+	err = transactor.InTransaction(ctx, func(ctx context.Context) error { return core.NewErrBadRequest() })
+	assertError(t, err, core.NewErrBadRequest())
+	if commits != 1 {
+		t.Errorf("%d hook calls, want none for a rolled back unit of work", commits-1)
+	}
+
+	// Stacked hooks each see every commit.
+	stacked := 0
+	transactor = OnCommit(transactor, func(context.Context) { stacked++ })
+	if err := transactor.InTransaction(ctx, func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if commits != 2 || stacked != 1 {
+		t.Errorf("hook calls = %d and %d, want 2 and 1", commits, stacked)
+	}
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+
+	// dispatchBatch is how many deliveries the dispatcher claims and sends
+	// concurrently.
+	dispatchBatch = 20
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *core.Webhook) error
+	ListWebhooks(ctx context.Context) ([]*core.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]*core.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, webhookID int, id int64) error
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Time) ([]*core.WebhookDelivery, error)
+	CompleteDelivery(ctx context.Context, delivery *core.WebhookDelivery) error
+}
+
+type WebhookService struct {
+	webhookRepository WebhookRepository
+}
+
+func NewWebhookService(webhookRepository WebhookRepository) *WebhookService {
+	return &WebhookService{webhookRepository: webhookRepository}
+}
+
+// CreateWebhook registers a webhook. Its host must resolve to public
+// addresses only. A secret is generated when none is given, it is returned
+// only here.
+func (service *WebhookService) CreateWebhook(ctx context.Context, webhook *core.Webhook) error {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return core.NewErrInvalidWebhookURL()
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return core.NewErrInvalidWebhookURL()
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return core.NewErrInvalidWebhookURL()
+		}
+	}
+
+	if len(webhook.Events) == 0 {
+		return core.NewErrBadRequest()
+	}
+	for _, name := range webhook.Events {
+		if !core.ValidEventName(name) {
+			return core.NewErrUnknownEvent(name)
+		}
+	}
+	slices.Sort(webhook.Events)
+	webhook.Events = slices.Compact(webhook.Events)
+
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logging.From(ctx).WithError(err).Error("could not generate webhook secret")
+			return core.NewErrInternal()
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	return service.webhookRepository.CreateWebhook(ctx, webhook)
+}
+
+func (service *WebhookService) ListWebhooks(ctx context.Context) ([]*core.Webhook, error) {
+	return service.webhookRepository.ListWebhooks(ctx)
+}
+
+func (service *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	return service.webhookRepository.DeleteWebhook(ctx, id)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook, optionally only
+// those with the given status.
+func (service *WebhookService) ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]*core.WebhookDelivery, error) {
+	switch status {
+	case core.DeliveryPending, core.DeliveryDelivered, core.DeliveryDead, "":
+	default:
+		return nil, core.NewErrUnknownDeliveryStatus(status)
+	}
+
+	if limit < 0 {
+		return nil, core.NewErrBadRequest()
+	}
+	if limit == 0 {
+		limit = defaultDeliveryLimit
+	}
+
+	return service.webhookRepository.ListDeliveries(ctx, webhookID, status, min(limit, maxDeliveryLimit))
+}
+
+// RetryDelivery sends a dead delivery again.
+func (service *WebhookService) RetryDelivery(ctx context.Context, webhookID int, id int64) error {
+	return service.webhookRepository.RetryDelivery(ctx, webhookID, id)
+}
+
+// sharedAddrs is the carrier-grade NAT range, private though netip does not
+// count it so.
+var sharedAddrs = netip.MustParsePrefix("100.64.0.0/10")
+
+// publicAddr tells whether webhooks may be sent to addr. Loopback, private,
+// link-local, unspecified and multicast addresses are refused, so that
+// webhooks can not reach hosts inside the network of the server, such as
+// cloud metadata endpoints.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !sharedAddrs.Contains(addr)
+}
+
+// NewWebhookClient returns the client webhooks are sent with. Its dialer
+// checks every address it connects to with publicAddr, which also covers
+// redirects and DNS answers that changed since the webhook was registered.
+// Proxies are not used, they would hide the address.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook target %s is not a public address", addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// WebhookDispatcher sends the deliveries queued in the outbox. Failed ones
+// are retried with exponential backoff until maxAttempts, then they are
+// dead.
+type WebhookDispatcher struct {
+	webhookRepository WebhookRepository
+	client            *http.Client
+	interval          time.Duration
+	backoff           time.Duration
+	maxBackoff        time.Duration
+	maxAttempts       int
+}
+
+func NewWebhookDispatcher(webhookRepository WebhookRepository, client *http.Client, interval, backoff, maxBackoff time.Duration, maxAttempts int) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepository: webhookRepository,
+		client:            client,
+		interval:          interval,
+		backoff:           backoff,
+		maxBackoff:        maxBackoff,
+		maxAttempts:       maxAttempts,
+	}
+}
+
+// Run dispatches due deliveries once per interval until ctx is done.
+func (dispatcher *WebhookDispatcher) Run(ctx context.Context) {
+	ctx = logging.WithFields(ctx, log.Fields{"job": "webhooks"})
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := dispatcher.Dispatch(ctx); err != nil {
+			logging.From(ctx).WithError(err).Error("webhook dispatch failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends every delivery that is due.
+func (dispatcher *WebhookDispatcher) Dispatch(ctx context.Context) error {
+	for {
+		// The lease outlasts a request, so a delivery is only claimed again
+		// when the dispatcher that claimed it died.
+		lease := time.Now().Add(dispatcher.client.Timeout + time.Minute)
+		deliveries, err := dispatcher.webhookRepository.ClaimDeliveries(ctx, dispatchBatch, lease)
+		if err != nil {
+			return err
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dispatcher.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < dispatchBatch {
+			return nil
+		}
+	}
+}
+
+func (dispatcher *WebhookDispatcher) attempt(ctx context.Context, delivery *core.WebhookDelivery) {
+	ctx = logging.WithFields(ctx, log.Fields{"webhook_id": delivery.WebhookId, "delivery_id": delivery.Id})
+	err := dispatcher.send(ctx, delivery)
+	delivery.Attempts++
+
+	switch {
+	case err == nil:
+		delivery.Status = core.DeliveryDelivered
+		delivery.NextAttemptAt = time.Now()
+		delivery.LastError = ""
+	case delivery.Attempts >= dispatcher.maxAttempts:
+		logging.From(ctx).WithError(err).Warn("webhook delivery is dead")
+		delivery.Status = core.DeliveryDead
+		delivery.NextAttemptAt = time.Now()
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = time.Now().Add(dispatcher.delay(delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	if err := dispatcher.webhookRepository.CompleteDelivery(ctx, delivery); err != nil {
+		logging.From(ctx).WithError(err).Error("could not record webhook delivery")
+	}
+}
+
+// delay is the backoff after the given number of failed attempts.
+func (dispatcher *WebhookDispatcher) delay(attempts int) time.Duration {
+	delay := dispatcher.backoff
+	for i := 1; i < attempts && delay < dispatcher.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, dispatcher.maxBackoff)
+}
+
+// send POSTs the payload of a delivery, any status but 2xx is a failure.
+func (dispatcher *WebhookDispatcher) send(ctx context.Context, delivery *core.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Filmoteka-Event", delivery.EventType)
+	req.Header.Set("X-Filmoteka-Delivery", strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set("X-Filmoteka-Timestamp", timestamp)
+	req.Header.Set("X-Filmoteka-Signature", SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook computes the X-Filmoteka-Signature header: the hex HMAC-SHA256
+// of the timestamp, a dot and the body, keyed with the webhook secret.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
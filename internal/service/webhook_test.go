@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+)
+
+// webhookReceiver is a partner endpoint that checks the signature of every
+// delivery it receives and answers with status.
+type webhookReceiver struct {
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	status   int
+	received []*http.Request
+	events   []*core.Event
+}
+
+func (receiver *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		receiver.t.Errorf("reading delivery: %v", err)
+	}
+
+	timestamp := r.Header.Get("X-Filmoteka-Timestamp")
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+		receiver.t.Errorf("timestamp %q: %v", timestamp, err)
+	}
+	if got, want := r.Header.Get("X-Filmoteka-Signature"), SignWebhook(receiver.secret, timestamp, body); got != want {
+		receiver.t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	event := &core.Event{}
+	if err := json.Unmarshal(body, event); err != nil {
+		receiver.t.Errorf("payload %s: %v", body, err)
+	}
+	if got := r.Header.Get("X-Filmoteka-Event"); got != event.Name() {
+		receiver.t.Errorf("event header = %q, want %q", got, event.Name())
+	}
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.received = append(receiver.received, r)
+	receiver.events = append(receiver.events, event)
+	w.WriteHeader(receiver.status)
+}
+
+func (receiver *webhookReceiver) count() int {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	return len(receiver.received)
+}
+
+func (receiver *webhookReceiver) answer(status int) {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	receiver.status = status
+}
+
+// newWebhookTest registers a webhook for movie.created pointing at a test
+// receiver and queues one delivery by creating a movie. The webhook is
+// stored directly, the service refuses the loopback address of the
+// receiver.
+func newWebhookTest(t *testing.T, status int) (*memory.WebhookRepository, *webhookReceiver, *http.Client) {
+	t.Helper()
+
+	store := memory.NewStore()
+	webhooks := memory.NewWebhookRepository(store)
+	movies := NewMovieService(memory.NewMovieRepository(store), memory.NewTransactor(store))
+
+	receiver := &webhookReceiver{t: t, secret: "s3cret", status: status}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	webhook := &core.Webhook{URL: server.URL + "/filmoteka", Events: []string{"movie.created"}, Secret: receiver.secret}
+	if err := webhooks.CreateWebhook(context.Background(), webhook); err != nil {
+		t.Fatal(err)
+	}
+	createMovie(t, movies, "Sherlock", 9, "2010-07-25")
+
+	return webhooks, receiver, server.Client()
+}
+
+func listDeliveries(t *testing.T, webhooks *memory.WebhookRepository) []*core.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := webhooks.ListDeliveries(context.Background(), 1, "", defaultDeliveryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries, want 1", len(deliveries))
+	}
+	return deliveries
+}
+
+func dispatch(t *testing.T, dispatcher *WebhookDispatcher) {
+	t.Helper()
+
+	if err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+
+	t.Run("signed delivery", func(t *testing.T) {
+		webhooks, receiver, client := newWebhookTest(t, http.StatusNoContent)
+		dispatcher := NewWebhookDispatcher(webhooks, client, time.Minute, time.Second, time.Minute, 3)
+
+		dispatch(t, dispatcher)
+		dispatch(t, dispatcher)
+
+		if receiver.count() != 1 {
+			t.Fatalf("%d requests received, want 1", receiver.count())
+		}
+		request, event := receiver.received[0], receiver.events[0]
+		if request.URL.Path != "/filmoteka" || request.Header.Get("X-Filmoteka-Delivery") != "1" {
+			t.Errorf("request = %s %s, delivery %q", request.Method, request.URL, request.Header.Get("X-Filmoteka-Delivery"))
+		}
+		if event.Entity != core.AuditMovie || event.EntityId != 1 || event.Type != core.EventCreated {
+			t.Errorf("event = %+v", event)
+		}
+
+		delivery := listDeliveries(t, webhooks)[0]
+		if delivery.Status != core.DeliveryDelivered || delivery.Attempts != 1 || delivery.LastError != "" {
+			t.Errorf("delivery = %s after %d attempts, error %q", delivery.Status, delivery.Attempts, delivery.LastError)
+		}
+	})
+
+	t.Run("failed delivery backs off", func(t *testing.T) {
+		webhooks, receiver, client := newWebhookTest(t, http.StatusInternalServerError)
+		dispatcher := NewWebhookDispatcher(webhooks, client, time.Minute, time.Hour, 4*time.Hour, 3)
+
+		before := time.Now()
+		dispatch(t, dispatcher)
+		after := time.Now()
+
+		delivery := listDeliveries(t, webhooks)[0]
+		if delivery.Status != core.DeliveryPending || delivery.Attempts != 1 || delivery.LastError != "webhook answered 500 Internal Server Error" {
+			t.Errorf("delivery = %s after %d attempts, error %q", delivery.Status, delivery.Attempts, delivery.LastError)
+		}
+		if delivery.NextAttemptAt.Before(before.Add(time.Hour)) || delivery.NextAttemptAt.After(after.Add(time.Hour)) {
+			t.Errorf("next attempt at %s, want an hour after %s", delivery.NextAttemptAt, before)
+		}
+
+		dispatch(t, dispatcher)
+		if receiver.count() != 1 {
+			t.Errorf("%d requests received, want no retry before the backoff", receiver.count())
+		}
+	})
+
+	t.Run("dead after max attempts", func(t *testing.T) {
+		webhooks, receiver, client := newWebhookTest(t, http.StatusServiceUnavailable)
+		dispatcher := NewWebhookDispatcher(webhooks, client, time.Minute, 0, 0, 3)
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			dispatch(t, dispatcher)
+		}
+
+		delivery := listDeliveries(t, webhooks)[0]
+		if delivery.Status != core.DeliveryDead || delivery.Attempts != 3 || delivery.LastError != "webhook answered 503 Service Unavailable" {
+			t.Fatalf("delivery = %s after %d attempts, error %q", delivery.Status, delivery.Attempts, delivery.LastError)
+		}
+
+		dispatch(t, dispatcher)
+		if receiver.count() != 3 {
+			t.Fatalf("%d requests received, want none after the delivery died", receiver.count())
+		}
+
+		ctx := context.Background()
+		if err := NewWebhookService(webhooks).RetryDelivery(ctx, 1, delivery.Id); err != nil {
+			t.Fatal(err)
+		}
+		receiver.answer(http.StatusOK)
+		dispatch(t, dispatcher)
+
+		delivery = listDeliveries(t, webhooks)[0]
+		if delivery.Status != core.DeliveryDelivered || delivery.Attempts != 1 || receiver.count() != 4 {
+			t.Errorf("retried delivery = %s after %d attempts, %d requests received", delivery.Status, delivery.Attempts, receiver.count())
+		}
+	})
+}
+
+func TestWebhookDelay(t *testing.T) {
+
+	dispatcher := NewWebhookDispatcher(nil, nil, time.Minute, time.Second, 10*time.Second, 8)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{60, 10 * time.Second},
+	}
+
+	for _, test := range tests {
+		if got := dispatcher.delay(test.attempts); got != test.want {
+			t.Errorf("delay(%d) = %s, want %s", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"203.0.113.10", true},
+		{"2001:db8::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"192.168.0.1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, test := range tests {
+		if got := publicAddr(netip.MustParseAddr(test.addr)); got != test.want {
+			t.Errorf("publicAddr(%s) = %v, want %v", test.addr, got, test.want)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 
 type ActorService interface {
 	CreateActor(ctx context.Context, actor *core.Actor) error
-	UpdateActor(ctx context.Context, id int, columnName string, newValue interface{}) error
-	DeleteActor(ctx context.Context, id int) error
+	UpdateActor(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	DeleteActor(ctx context.Context, id int, version int) error
+	RestoreActor(ctx context.Context, id int) error
+	GetActor(ctx context.Context, id int) (*core.Actor, error)
 	GetAllActors(ctx context.Context) ([]*core.Actor, error)
 }
 
@@ -23,4 +25,134 @@ func NewActorHandler(service ActorService) *ActorHandler {
 
 func (handler *ActorHandler) CreateActor(w http.ResponseWriter, r *http.Request) {
 
+	actor := &core.Actor{}
+	if err := decodeBody(r, actor); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.actorService.CreateActor(r.Context(), actor); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// A new actor played in no movies yet.
+	actor.Movies = []int{}
+	writeJSON(w, http.StatusCreated, actor)
+}
+
+func (handler *ActorHandler) GetActor(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actor, err := handler.actorService.GetActor(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	etag := makeETag(actor.Version, "")
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, actor)
+}
+
+func (handler *ActorHandler) GetActors(w http.ResponseWriter, r *http.Request) {
+
+	actors, err := handler.actorService.GetAllActors(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if actors == nil {
+		actors = []*core.Actor{}
+	}
+
+	writeJSON(w, http.StatusOK, actors)
+}
+
+func (handler *ActorHandler) UpdateActor(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r, handler.currentVersion(r, id))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	update := &UpdateRequest{}
+	if err := decodeBody(r, update); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.actorService.UpdateActor(r.Context(), id, version, update.Column, updateValue(update.Value)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *ActorHandler) DeleteActor(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r, handler.currentVersion(r, id))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.actorService.DeleteActor(r.Context(), id, version); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *ActorHandler) RestoreActor(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.actorService.RestoreActor(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentVersion looks up the version of the actor for ifMatchVersion.
+func (handler *ActorHandler) currentVersion(r *http.Request, id int) func() (int, error) {
+	return func() (int, error) {
+		actor, err := handler.actorService.GetActor(r.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return actor.Version, nil
+	}
 }
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+
+	"filmoteka/internal/core"
+)
+
+func TestActorHandler(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		status  int
+		wantErr *core.MyError
+	}{
+		{"create", http.MethodPost, "/actors", `{"name":"Andrew Scott","sex":77,"bd":"1976-10-21"}`, http.StatusCreated, nil},
+		{"create a duplicate name", http.MethodPost, "/actors", `{"name":"Martin Freeman","sex":77,"bd":"1971-09-08"}`, 0, core.NewErrActorAlreadyExists()},
+		{"create from bad json", http.MethodPost, "/actors", `[]`, 0, core.NewErrBadRequest()},
+		{"get", http.MethodGet, "/actors/1", "", http.StatusOK, nil},
+		{"get a missing actor", http.MethodGet, "/actors/42", "", 0, core.NewErrActorDoesNotExist()},
+		{"list", http.MethodGet, "/actors", "", http.StatusOK, nil},
+		{"update", http.MethodPatch, "/actors/1", `{"column":"bd","value":"1976-07-20"}`, http.StatusNoContent, nil},
+		{"update to a duplicate name", http.MethodPatch, "/actors/1", `{"column":"name","value":"Martin Freeman"}`, 0, core.NewErrActorAlreadyExists()},
+		{"update an unknown column", http.MethodPatch, "/actors/1", `{"column":"movies","value":[2]}`, 0, core.NewErrUnknownColumn()},
+		{"update a missing actor", http.MethodPatch, "/actors/42", `{"column":"bd","value":"1976-07-20"}`, 0, core.NewErrActorDoesNotExist()},
+		{"delete", http.MethodDelete, "/actors/2", "", http.StatusNoContent, nil},
+		{"delete a missing actor", http.MethodDelete, "/actors/42", "", 0, core.NewErrActorDoesNotExist()},
+		{"restore a live actor", http.MethodPost, "/actors/1/restore", "", http.StatusNoContent, nil},
+		{"restore a missing actor", http.MethodPost, "/actors/42/restore", "", 0, core.NewErrActorDoesNotExist()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			server.seed(t)
+
+			response := server.do(t, test.method, test.path, test.body)
+			if test.wantErr != nil {
+				response.expectError(t, test.wantErr)
+				return
+			}
+			response.expect(t, test.status)
+		})
+	}
+}
+
+// Changes sent with If-Match apply only to the version the ETag names.
+func TestActorHandlerIfMatch(t *testing.T) {
+
+	server := newTestServer(t)
+	server.seed(t)
+
+	etag := server.do(t, http.MethodGet, "/actors/2", "").expect(t, http.StatusOK).Header().Get("ETag")
+
+	server.do(t, http.MethodPatch, "/actors/2", `{"column":"bd","value":"1971-09-09"}`, "If-Match", etag).expect(t, http.StatusNoContent)
+	server.do(t, http.MethodPatch, "/actors/2", `{"column":"bd","value":"1971-09-10"}`, "If-Match", etag).expectError(t, core.NewErrVersionMismatch())
+	server.do(t, http.MethodDelete, "/actors/2", "", "If-Match", etag).expectError(t, core.NewErrVersionMismatch())
+
+	// A list matches when any of its tags does.
+	current := server.do(t, http.MethodGet, "/actors/2", "").expect(t, http.StatusOK).Header().Get("ETag")
+	server.do(t, http.MethodPatch, "/actors/2", `{"column":"bd","value":"1971-09-10"}`, "If-Match", `"99", `+etag+`, W/"x"`).expectError(t, core.NewErrVersionMismatch())
+	server.do(t, http.MethodPatch, "/actors/2", `{"column":"bd","value":"1971-09-10"}`, "If-Match", `"99", `+current).expect(t, http.StatusNoContent)
+	server.do(t, http.MethodPatch, "/actors/42", `{"column":"bd","value":"1971-09-10"}`, "If-Match", `"99", `+current).expectError(t, core.NewErrActorDoesNotExist())
+
+	server.do(t, http.MethodDelete, "/actors/2", "", "If-Match", "*").expect(t, http.StatusNoContent)
+}
+
+// Deleting an actor takes it out of the cast of its movies until it is
+// restored.
+func TestActorHandlerCascade(t *testing.T) {
+
+	server := newTestServer(t)
+	server.seed(t)
+
+	server.do(t, http.MethodDelete, "/actors/1", "").expect(t, http.StatusNoContent)
+	server.do(t, http.MethodGet, "/actors/1", "").expectError(t, core.NewErrActorDoesNotExist())
+
+	var movie core.MovieDetail
+	server.do(t, http.MethodGet, "/movies/1", "").expect(t, http.StatusOK).decode(t, &movie)
+	if len(movie.Actors) != 0 {
+		t.Errorf("cast = %+v, want the deleted actor left out", movie.Actors)
+	}
+
+	server.do(t, http.MethodPost, "/movies/1/actors", `{"actors":[{"actor_id":1}]}`).expectError(t, core.NewErrActorsDoNotExist([]int{1}))
+
+	server.do(t, http.MethodPost, "/actors/1/restore", "").expect(t, http.StatusNoContent)
+	server.do(t, http.MethodGet, "/movies/1", "").expect(t, http.StatusOK).decode(t, &movie)
+	if len(movie.Actors) != 1 || movie.Actors[0].Id != 1 {
+		t.Errorf("cast = %+v, want the restored actor back", movie.Actors)
+	}
+}
@@ -0,0 +1,151 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"net/http"
+	"strings"
+)
+
+// apiKeyScheme is the Authorization scheme API keys are sent with.
+const apiKeyScheme = "ApiKey"
+
+type APIKeyService interface {
+	IssueAPIKey(ctx context.Context, key *core.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]*core.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, raw string) (*core.APIKey, error)
+}
+
+type APIKeyHandler struct {
+	apiKeyService APIKeyService
+}
+
+func NewAPIKeyHandler(service APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: service}
+}
+
+// IssueAPIKey generates a key, the response is the only one that contains
+// it.
+func (handler *APIKeyHandler) IssueAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	key := &core.APIKey{}
+	if err := decodeBody(r, key); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.apiKeyService.IssueAPIKey(r.Context(), key); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (handler *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+
+	keys, err := handler.apiKeyService.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+func (handler *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.apiKeyService.RevokeAPIKey(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Authenticator checks the API keys requests present and the scopes routes
+// require.
+type Authenticator struct {
+	apiKeyService APIKeyService
+	required      bool
+}
+
+func NewAuthenticator(service APIKeyService, cfg *config.AuthConfig) *Authenticator {
+	return &Authenticator{apiKeyService: service, required: cfg.Required}
+}
+
+// withAPIKey attaches the key sent in an "Authorization: ApiKey" header to
+// the request context and records its name as the user of the request, the
+// one the audit log records changes under. Requests without a key are
+// anonymous. An invalid key is rejected even where no key is required.
+func (auth *Authenticator) withAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := presentedAPIKey(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, err := auth.apiKeyService.Authenticate(r.Context(), raw)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		r = withRequestUser(r.WithContext(core.WithAPIKey(r.Context(), key)), "apikey:"+key.Name)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// presentedAPIKey returns the key sent in an "Authorization: ApiKey"
+// header, unchecked.
+func presentedAPIKey(r *http.Request) (string, bool) {
+	scheme, raw, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, apiKeyScheme) {
+		return "", false
+	}
+	return strings.TrimSpace(raw), true
+}
+
+// require serves next to requests whose key grants every one of scopes.
+// Requests without a key are served only while keys are not required, and
+// never where the admin scope is.
+func (auth *Authenticator) require(next http.HandlerFunc, scopes ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.required && core.APIKeyFrom(r.Context()) == nil {
+			writeError(w, r, core.NewErrUnauthorized())
+			return
+		}
+
+		for _, scope := range scopes {
+			if err := checkScope(r.Context(), scope); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+
+		next(w, r)
+	})
+}
+
+// checkScope fails when the request was made with a key lacking scope.
+// Requests let through without a key have the movie and actor scopes but
+// never admin, the first admin key is issued with the CLI.
+func checkScope(ctx context.Context, scope string) error {
+	key := core.APIKeyFrom(ctx)
+	if key == nil && scope == core.ScopeAdmin {
+		return core.NewErrUnauthorized()
+	}
+	if key != nil && !key.HasScope(scope) {
+		return core.NewErrForbidden(scope)
+	}
+	return nil
+}
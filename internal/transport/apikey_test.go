@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+	"filmoteka/internal/service"
+)
+
+func TestAuthenticatorRequired(t *testing.T) {
+
+	apiKeys := service.NewAPIKeyService(memory.NewAPIKeyRepository())
+	key := &core.APIKey{Name: "reader", Scopes: []string{core.ScopeMoviesRead}}
+	if err := apiKeys.IssueAPIKey(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuthenticator(apiKeys, &config.AuthConfig{Required: true})
+	handler := auth.withAPIKey(auth.require(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, core.ScopeMoviesRead))
+
+	tests := []struct {
+		name    string
+		headers []string
+		status  int
+	}{
+		{"without a key", nil, http.StatusUnauthorized},
+		{"with an invalid key", []string{"Authorization", apiKeyScheme + " not-a-key"}, http.StatusUnauthorized},
+		{"with a key", []string{"Authorization", apiKeyScheme + " " + key.Key}, http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newRequest(http.MethodGet, "/movies", "", test.headers...))
+			if w.Code != test.status {
+				t.Errorf("status = %d, want %d", w.Code, test.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"net/http"
+	"strconv"
+)
+
+type AuditService interface {
+	ListAudit(ctx context.Context, filter core.AuditFilter) (*core.AuditPage, error)
+}
+
+type AuditHandler struct {
+	auditService AuditService
+}
+
+func NewAuditHandler(service AuditService) *AuditHandler {
+	return &AuditHandler{auditService: service}
+}
+
+// GetAudit pages through the audit log. The next page starts after the id
+// returned in next.
+func (handler *AuditHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+	filter := core.AuditFilter{Entity: query.Get("entity")}
+
+	var err error
+	if filter.EntityId, err = queryInt(r, "id"); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if filter.Limit, err = queryInt(r, "limit"); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	after, err := queryInt(r, "after")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	filter.After = int64(after)
+
+	page, err := handler.auditService.ListAudit(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// queryInt reads an optional integer query parameter, 0 when it is absent.
+func queryInt(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, core.NewErrBadRequest()
+	}
+	return n, nil
+}
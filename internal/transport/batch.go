@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"net/http"
+)
+
+type BatchService interface {
+	Run(ctx context.Context, operations []core.BatchOperation) ([]core.BatchResult, error)
+}
+
+// BatchRequest lists the operations of a batch in the order they run.
+type BatchRequest struct {
+	Operations []core.BatchOperation `json:"operations"`
+}
+
+// BatchResponse holds one result per operation.
+type BatchResponse struct {
+	Results []core.BatchResult `json:"results"`
+}
+
+type BatchHandler struct {
+	batchService BatchService
+}
+
+func NewBatchHandler(service BatchService) *BatchHandler {
+	return &BatchHandler{batchService: service}
+}
+
+// RunBatch applies all operations of the request or none of them.
+func (handler *BatchHandler) RunBatch(w http.ResponseWriter, r *http.Request) {
+
+	request := &BatchRequest{}
+	if err := decodeBody(r, request); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	for i := range request.Operations {
+		if scope := batchScope(request.Operations[i].Op); scope != "" {
+			if err := checkScope(r.Context(), scope); err != nil {
+				writeError(w, r, &core.BatchError{Index: i, Err: err})
+				return
+			}
+		}
+		request.Operations[i].Value = updateValue(request.Operations[i].Value)
+	}
+
+	results, err := handler.batchService.Run(r.Context(), request.Operations)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BatchResponse{Results: results})
+}
+
+// batchScope is the scope an operation needs. Links change the cast of a
+// movie. Unknown operations are left to the service to reject.
+func batchScope(op string) string {
+	switch op {
+	case core.BatchCreateActor, core.BatchUpdateActor, core.BatchDeleteActor:
+		return core.ScopeActorsWrite
+	case core.BatchCreateMovie, core.BatchUpdateMovie, core.BatchDeleteMovie, core.BatchLink, core.BatchUnlink:
+		return core.ScopeMoviesWrite
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// eventPage is how many events are read from the log at once.
+	eventPage = 100
+	// eventPoll is how often a stream checks the log without being woken
+	// up, which picks up changes made through other instances, and sends a
+	// keep-alive comment.
+	eventPoll = 15 * time.Second
+)
+
+type EventService interface {
+	Subscribe() (<-chan struct{}, func())
+	Events(ctx context.Context, after int64, entity string, limit int) ([]*core.Event, int64, error)
+}
+
+type EventHandler struct {
+	eventService EventService
+}
+
+func NewEventHandler(service EventService) *EventHandler {
+	return &EventHandler{eventService: service}
+}
+
+// GetEvents streams changes as Server-Sent Events. The stream resumes after
+// the id in the Last-Event-ID header, or the last_event_id query parameter
+// for clients that can not set headers, and starts at the beginning of the
+// log without either.
+func (handler *EventHandler) GetEvents(w http.ResponseWriter, r *http.Request) {
+
+	entity, err := eventEntity(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if entity, err = readableEntity(r.Context(), entity); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	after, err := lastEventID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Subscribe before the first read so that no notification is missed.
+	wake, unsubscribe := handler.eventService.Subscribe()
+	defer unsubscribe()
+
+	ctx := r.Context()
+	events, next, err := handler.eventService.Events(ctx, after, entity, eventPage)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(eventPoll)
+	defer ticker.Stop()
+
+	for {
+		for {
+			for _, event := range events {
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			}
+			if next == after {
+				break
+			}
+
+			after = next
+			if events, next, err = handler.eventService.Events(ctx, after, entity, eventPage); err != nil {
+				logging.From(ctx).WithError(err).Warn("event stream ended")
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+
+		if events, next, err = handler.eventService.Events(ctx, after, entity, eventPage); err != nil {
+			logging.From(ctx).WithError(err).Warn("event stream ended")
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *core.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.WithError(err).Error("could not encode event")
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Name(), data)
+	return err
+}
+
+// eventEntity reads the comma separated entity query parameter. Naming both
+// entities is the same as naming none.
+func eventEntity(r *http.Request) (string, error) {
+	entity, both := "", false
+	for _, name := range strings.Split(r.URL.Query().Get("entity"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if name != core.AuditMovie && name != core.AuditActor {
+			return "", core.NewErrUnknownEntity(name)
+		}
+		both = both || (entity != "" && entity != name)
+		entity = name
+	}
+
+	if both {
+		return "", nil
+	}
+	return entity, nil
+}
+
+// readableEntity narrows the events of both entities to the one the key of
+// the request may read, and fails when it may read neither or not the
+// entity asked for.
+func readableEntity(ctx context.Context, entity string) (string, error) {
+	movies := checkScope(ctx, core.ScopeMoviesRead)
+	actors := checkScope(ctx, core.ScopeActorsRead)
+
+	switch {
+	case entity == core.AuditMovie:
+		return entity, movies
+	case entity == core.AuditActor:
+		return entity, actors
+	case movies != nil && actors != nil:
+		return "", movies
+	case movies != nil:
+		return core.AuditActor, nil
+	case actors != nil:
+		return core.AuditMovie, nil
+	}
+	return entity, nil
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, core.NewErrBadRequest()
+	}
+	return id, nil
+}
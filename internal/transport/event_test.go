@@ -0,0 +1,53 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventHandler(t *testing.T) {
+
+	server := newTestServer(t)
+	server.seed(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []string
+		want    []string
+	}{
+		{"from the start", "/events", nil, []string{"id: 1\nevent: actor.created", "id: 2\nevent: actor.created", "id: 3\nevent: movie.created", "id: 4\nevent: movie.created"}},
+		{"after Last-Event-ID", "/events", []string{"Last-Event-ID", "3"}, []string{"id: 4\nevent: movie.created"}},
+		{"after last_event_id", "/events?last_event_id=3", nil, []string{"id: 4\nevent: movie.created"}},
+		{"of one entity", "/events?entity=actor", nil, []string{"id: 1\nevent: actor.created", "id: 2\nevent: actor.created"}},
+		{"of both entities", "/events?entity=movie,actor&last_event_id=2", nil, []string{"id: 3\nevent: movie.created", "id: 4\nevent: movie.created"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := newRequest(http.MethodGet, test.path, "", test.headers...)
+			ctx, cancel := context.WithTimeout(r.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			response := server.serve(r.WithContext(ctx)).expect(t, http.StatusOK)
+			if got := response.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Errorf("Content-Type = %q, want text/event-stream", got)
+			}
+
+			var got []string
+			for _, event := range strings.Split(strings.TrimSpace(response.Body.String()), "\n\n") {
+				if lines := strings.SplitN(event, "\n", 3); len(lines) == 3 {
+					got = append(got, lines[0]+"\n"+lines[1])
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(test.want, ",") {
+				t.Errorf("events = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	server.do(t, http.MethodGet, "/events", "", "Last-Event-ID", "-1").expect(t, http.StatusBadRequest)
+}
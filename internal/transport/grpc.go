@@ -0,0 +1,177 @@
+package transport
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "filmoteka/pkg/api/filmoteka/v1"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// NewGRPCServer builds a gRPC server exposing movie and actor services. Every
+// call goes through logging, error mapping and bearer token auth, in that order.
+func NewGRPCServer(cfg *config.GRPCConfig, movieService MovieService, actorService ActorService) *grpc.Server {
+
+	auth := &grpcAuth{token: cfg.Token}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryLoggingInterceptor, unaryErrorInterceptor, auth.unary),
+		grpc.ChainStreamInterceptor(streamLoggingInterceptor, streamErrorInterceptor, auth.stream),
+	)
+
+	pb.RegisterMovieServiceServer(server, NewMovieGRPCServer(movieService))
+	pb.RegisterActorServiceServer(server, NewActorGRPCServer(actorService))
+
+	return server
+}
+
+type grpcAuth struct {
+	token string
+}
+
+func (auth *grpcAuth) check(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok || auth.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(auth.token)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return nil
+}
+
+func (auth *grpcAuth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := auth.check(ctx); err != nil {
+		return nil, err
+	}
+	return handler(withGRPCUser(ctx), req)
+}
+
+// withGRPCUser records the caller identified by the token as the user of
+// ctx, the audit log records it with every change.
+func withGRPCUser(ctx context.Context) context.Context {
+	ctx = logging.WithFields(ctx, log.Fields{"user": core.GRPCUser})
+	return core.WithUser(ctx, core.GRPCUser)
+}
+
+func (auth *grpcAuth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := auth.check(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: withGRPCUser(ss.Context())})
+}
+
+// contextStream is a server stream whose handler sees ctx, the way unary
+// interceptors pass a context on.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
+
+func unaryLoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx = withGRPCRequestLog(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	logGRPCCall(ctx, start, err)
+	return resp, err
+}
+
+func streamLoggingInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withGRPCRequestLog(ss.Context(), info.FullMethod)
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logGRPCCall(ctx, start, err)
+	return err
+}
+
+// withGRPCRequestLog attaches a logger carrying the method and the request
+// id, taken from the x-request-id metadata or generated, to ctx. The id is
+// sent back in the response header.
+func withGRPCRequestLog(ctx context.Context, method string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := ""
+	if values := md.Get(requestIDHeader); len(values) > 0 {
+		id = values[0]
+	}
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+	return logging.WithFields(ctx, log.Fields{"request_id": id, "method": method})
+}
+
+func logGRPCCall(ctx context.Context, start time.Time, err error) {
+	logging.From(ctx).WithFields(log.Fields{
+		"code":     status.Code(err).String(),
+		"duration": time.Since(start),
+	}).Info("grpc call")
+}
+
+func unaryErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	return resp, toGRPCError(err)
+}
+
+func streamErrorInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return toGRPCError(handler(srv, ss))
+}
+
+// toGRPCError converts core.MyError into a status carrying the matching code.
+// Errors that already are statuses are passed through, anything else is Internal.
+func toGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var myErr *core.MyError
+	if errors.As(err, &myErr) {
+		return status.Error(grpcCode(myErr.Inf.StatusCode), myErr.Inf.Msg)
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	}
+	return codes.Internal
+}
@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"unicode/utf8"
+
+	pb "filmoteka/pkg/api/filmoteka/v1"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type ActorGRPCServer struct {
+	pb.UnimplementedActorServiceServer
+	actorService ActorService
+}
+
+func NewActorGRPCServer(service ActorService) *ActorGRPCServer {
+	return &ActorGRPCServer{actorService: service}
+}
+
+func (server *ActorGRPCServer) CreateActor(ctx context.Context, req *pb.CreateActorRequest) (*pb.Actor, error) {
+
+	actor := actorFromProto(req.GetActor())
+
+	if err := server.actorService.CreateActor(ctx, actor); err != nil {
+		return nil, err
+	}
+
+	return actorToProto(actor), nil
+}
+
+func (server *ActorGRPCServer) UpdateActor(ctx context.Context, req *pb.UpdateActorRequest) (*emptypb.Empty, error) {
+
+	var value interface{}
+	switch v := req.GetValue().(type) {
+	case *pb.UpdateActorRequest_StringValue:
+		value = v.StringValue
+	case *pb.UpdateActorRequest_IntValue:
+		value = int(v.IntValue)
+	}
+
+	if err := server.actorService.UpdateActor(ctx, int(req.GetId()), int(req.GetVersion()), req.GetColumn(), value); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *ActorGRPCServer) DeleteActor(ctx context.Context, req *pb.DeleteActorRequest) (*emptypb.Empty, error) {
+
+	if err := server.actorService.DeleteActor(ctx, int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *ActorGRPCServer) RestoreActor(ctx context.Context, req *pb.RestoreActorRequest) (*emptypb.Empty, error) {
+
+	if err := server.actorService.RestoreActor(ctx, int(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *ActorGRPCServer) ListActors(req *pb.ListActorsRequest, stream pb.ActorService_ListActorsServer) error {
+
+	actors, err := server.actorService.GetAllActors(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return sendActors(stream, actors)
+}
+
+func (server *ActorGRPCServer) ExportActors(req *pb.ExportActorsRequest, stream pb.ActorService_ExportActorsServer) error {
+
+	actors, err := server.actorService.GetAllActors(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return sendActors(stream, actors)
+}
+
+type actorStream interface {
+	Send(*pb.Actor) error
+}
+
+func sendActors(stream actorStream, actors []*core.Actor) error {
+	for _, actor := range actors {
+		if err := stream.Send(actorToProto(actor)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func actorToProto(actor *core.Actor) *pb.Actor {
+	movies := make([]int32, 0, len(actor.Movies))
+	for _, id := range actor.Movies {
+		movies = append(movies, int32(id))
+	}
+
+	sex := ""
+	if actor.Sex != 0 {
+		sex = string(actor.Sex)
+	}
+
+	return &pb.Actor{
+		Id:     int32(actor.Id),
+		Name:   actor.Name,
+		Sex:    sex,
+		Bd:     actor.Bd,
+		Movies: movies,
+	}
+}
+
+func actorFromProto(actor *pb.Actor) *core.Actor {
+	movies := make([]int, 0, len(actor.GetMovies()))
+	for _, id := range actor.GetMovies() {
+		movies = append(movies, int(id))
+	}
+
+	sex, _ := utf8.DecodeRuneInString(actor.GetSex())
+	if sex == utf8.RuneError {
+		sex = 0
+	}
+
+	return &core.Actor{
+		Id:     int(actor.GetId()),
+		Name:   actor.GetName(),
+		Sex:    sex,
+		Bd:     actor.GetBd(),
+		Movies: movies,
+	}
+}
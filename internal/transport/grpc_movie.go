@@ -0,0 +1,173 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/core"
+
+	pb "filmoteka/pkg/api/filmoteka/v1"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type MovieGRPCServer struct {
+	pb.UnimplementedMovieServiceServer
+	movieService MovieService
+}
+
+func NewMovieGRPCServer(service MovieService) *MovieGRPCServer {
+	return &MovieGRPCServer{movieService: service}
+}
+
+func (server *MovieGRPCServer) CreateMovie(ctx context.Context, req *pb.CreateMovieRequest) (*pb.Movie, error) {
+
+	movie := movieFromProto(req.GetMovie())
+
+	if err := server.movieService.CreateMovie(ctx, movie); err != nil {
+		return nil, err
+	}
+
+	return movieToProto(movie), nil
+}
+
+func (server *MovieGRPCServer) UpdateMovie(ctx context.Context, req *pb.UpdateMovieRequest) (*emptypb.Empty, error) {
+
+	var value interface{}
+	switch v := req.GetValue().(type) {
+	case *pb.UpdateMovieRequest_StringValue:
+		value = v.StringValue
+	case *pb.UpdateMovieRequest_IntValue:
+		value = int(v.IntValue)
+	}
+
+	if err := server.movieService.UpdateMovie(ctx, int(req.GetId()), int(req.GetVersion()), req.GetColumn(), value); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *MovieGRPCServer) DeleteMovie(ctx context.Context, req *pb.DeleteMovieRequest) (*emptypb.Empty, error) {
+
+	if err := server.movieService.DeleteMovie(ctx, int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *MovieGRPCServer) RestoreMovie(ctx context.Context, req *pb.RestoreMovieRequest) (*emptypb.Empty, error) {
+
+	if err := server.movieService.RestoreMovie(ctx, int(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *MovieGRPCServer) AddActors(ctx context.Context, req *pb.MovieActorsRequest) (*emptypb.Empty, error) {
+
+	if err := server.movieService.AddActors(ctx, int(req.GetMovieId()), castFromProto(req.GetCast())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *MovieGRPCServer) DeleteActors(ctx context.Context, req *pb.MovieActorsRequest) (*emptypb.Empty, error) {
+
+	if err := server.movieService.DeleteActors(ctx, int(req.GetMovieId()), castFromProto(req.GetCast())); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (server *MovieGRPCServer) ListMovies(req *pb.ListMoviesRequest, stream pb.MovieService_ListMoviesServer) error {
+
+	movies, err := server.movieService.GetAll(stream.Context(), req.GetSort())
+	if err != nil {
+		return err
+	}
+
+	return sendMovies(stream, movies)
+}
+
+func (server *MovieGRPCServer) SearchMovies(req *pb.SearchMoviesRequest, stream pb.MovieService_SearchMoviesServer) error {
+
+	movies, err := server.movieService.SearchMovie(stream.Context(), req.GetQuery())
+	if err != nil {
+		return err
+	}
+
+	return sendMovies(stream, movies)
+}
+
+// ExportMovies streams the whole catalogue ordered by title, which is stable
+// between calls and therefore suitable for bulk consumers.
+func (server *MovieGRPCServer) ExportMovies(req *pb.ExportMoviesRequest, stream pb.MovieService_ExportMoviesServer) error {
+
+	movies, err := server.movieService.GetAll(stream.Context(), "title")
+	if err != nil {
+		return err
+	}
+
+	return sendMovies(stream, movies)
+}
+
+type movieStream interface {
+	Send(*pb.Movie) error
+}
+
+func sendMovies(stream movieStream, movies []*core.Movie) error {
+	for _, movie := range movies {
+		if err := stream.Send(movieToProto(movie)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func movieToProto(movie *core.Movie) *pb.Movie {
+	actors := make([]int32, 0, len(movie.Actors))
+	for _, id := range movie.Actors {
+		actors = append(actors, int32(id))
+	}
+
+	return &pb.Movie{
+		Id:      int32(movie.Id),
+		Title:   movie.Title,
+		Descr:   movie.Descr,
+		Release: movie.Release,
+		Rating:  int32(movie.Rating),
+		Actors:  actors,
+	}
+}
+
+func movieFromProto(movie *pb.Movie) *core.Movie {
+	actors := make([]int, 0, len(movie.GetActors()))
+	for _, id := range movie.GetActors() {
+		actors = append(actors, int(id))
+	}
+
+	return &core.Movie{
+		Id:      int(movie.GetId()),
+		Title:   movie.GetTitle(),
+		Descr:   movie.GetDescr(),
+		Release: movie.GetRelease(),
+		Rating:  int(movie.GetRating()),
+		Actors:  actors,
+	}
+}
+
+func castFromProto(cast []*pb.CastMember) []core.CastMember {
+	members := make([]core.CastMember, 0, len(cast))
+	for _, member := range cast {
+		members = append(members, core.CastMember{
+			ActorId:   int(member.GetActorId()),
+			ActorName: member.GetActorName(),
+			Character: member.GetCharacter(),
+			Billing:   int(member.GetBilling()),
+		})
+	}
+	return members
+}
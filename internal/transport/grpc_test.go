@@ -0,0 +1,51 @@
+package transport
+
+import (
+	"context"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *testStream) Context() context.Context {
+	return stream.ctx
+}
+
+// Stream handlers see the request logger and the user, like unary ones.
+func TestGRPCStreamContext(t *testing.T) {
+
+	auth := &grpcAuth{token: "s3cret"}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer s3cret", "x-request-id", "req-42"))
+	stream := &testStream{ctx: ctx}
+	info := &grpc.StreamServerInfo{FullMethod: "/filmoteka.v1.MovieService/Watch"}
+
+	var fields map[string]interface{}
+	var user string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		fields = logging.From(stream.Context()).Data
+		user = core.UserFrom(stream.Context())
+		return nil
+	}
+
+	err := streamLoggingInterceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+		return auth.stream(srv, stream, info, handler)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields["request_id"] != "req-42" || fields["method"] != info.FullMethod || fields["user"] != core.GRPCUser {
+		t.Errorf("log fields = %v, want the request id, method and user", fields)
+	}
+	if user != core.GRPCUser {
+		t.Errorf("user = %q, want %q", user, core.GRPCUser)
+	}
+}
@@ -0,0 +1,181 @@
+package transport
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrorResponse is the body of every non-2xx response.
+type ErrorResponse struct {
+	Type    string   `json:"type"`
+	Message string   `json:"message"`
+	Ids     []int    `json:"ids,omitempty"`
+	Names   []string `json:"names,omitempty"`
+	// Operation is the index of the batch operation that failed.
+	Operation *int `json:"operation,omitempty"`
+}
+
+// UpdateRequest changes a single column of a movie or an actor.
+type UpdateRequest struct {
+	Column string      `json:"column"`
+	Value  interface{} `json:"value"`
+}
+
+// ActorsRequest lists actors to link to or unlink from a movie.
+type ActorsRequest struct {
+	Actors []core.CastMember `json:"actors"`
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.WithError(err).Warn("could not write response")
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var myErr *core.MyError
+	if !errors.As(err, &myErr) {
+		logging.From(r.Context()).WithError(err).Error("request failed")
+		myErr = core.NewErrInternal()
+	}
+
+	if myErr.Inf.StatusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", apiKeyScheme)
+	}
+
+	response := ErrorResponse{Type: myErr.Type, Message: myErr.Inf.Msg, Ids: myErr.Inf.Ids, Names: myErr.Inf.Names}
+
+	var batchErr *core.BatchError
+	if errors.As(err, &batchErr) {
+		response.Operation = &batchErr.Index
+	}
+
+	writeJSON(w, myErr.Inf.StatusCode, response)
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		logging.From(r.Context()).WithError(err).Debug("could not parse request body")
+		return core.NewErrBadRequest()
+	}
+	return nil
+}
+
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return 0, core.NewErrBadRequest()
+	}
+	return id, nil
+}
+
+// parseInclude reads the comma separated include query parameter, falling
+// back to defaults when it is absent. Unknown relations are rejected.
+func parseInclude(r *http.Request, allowed map[string]bool, defaults ...string) (map[string]bool, error) {
+	values, ok := r.URL.Query()["include"]
+	if !ok {
+		values = defaults
+	}
+
+	include := map[string]bool{}
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if !allowed[name] {
+				return nil, core.NewErrUnknownInclude(name)
+			}
+			include[name] = true
+		}
+	}
+
+	return include, nil
+}
+
+// makeETag renders a row version as a strong ETag. detail covers parts of
+// the representation the version does not, such as included relations.
+func makeETag(version int, detail string) string {
+	etag := strconv.Itoa(version)
+	if detail != "" {
+		sum := sha256.Sum256([]byte(detail))
+		etag += "." + hex.EncodeToString(sum[:8])
+	}
+	return `"` + etag + `"`
+}
+
+// ifMatchVersion extracts the row version from an If-Match header. It
+// returns 0, which skips the version check, when the header is absent or
+// "*". When the header lists tags of several versions, current looks up
+// the version of the row and it is returned if any tag names it; the
+// service still checks it, so a change in between fails the request.
+// Tags this server could not have issued never match.
+func ifMatchVersion(r *http.Request, current func() (int, error)) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	versions := map[int]bool{}
+	version := 0
+	for _, candidate := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(candidate)
+		if !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) || len(tag) < 2 {
+			continue
+		}
+
+		number, _, _ := strings.Cut(strings.Trim(tag, `"`), ".")
+		if v, err := strconv.Atoi(number); err == nil && v > 0 {
+			versions[v] = true
+			version = v
+		}
+	}
+
+	if len(versions) == 0 {
+		return 0, core.NewErrVersionMismatch()
+	}
+	if len(versions) == 1 {
+		return version, nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+	if !versions[version] {
+		return 0, core.NewErrVersionMismatch()
+	}
+	return version, nil
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// updateValue turns whole JSON numbers into ints so they can be bound to
+// integer columns.
+func updateValue(value interface{}) interface{} {
+	if f, ok := value.(float64); ok && f == math.Trunc(f) {
+		return int(f)
+	}
+	return value
+}
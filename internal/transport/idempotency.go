@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// maxIdempotencyKey matches the size of the key column.
+const maxIdempotencyKey = 255
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, client, key, hash string) (*core.StoredResponse, error)
+	Finish(ctx context.Context, client, key string, response *core.StoredResponse) error
+}
+
+// secretRoutes answer with secrets, issued API keys and webhook secrets,
+// which must not be stored. The Idempotency-Key header is ignored on them.
+var secretRoutes = map[string]bool{
+	"POST /admin/apikeys":  true,
+	"POST /admin/webhooks": true,
+}
+
+// withIdempotency answers a POST request carrying an Idempotency-Key header
+// once and replays that answer when the request is retried with the key.
+// Keys are scoped to the client, see requestClient, and reusing one for a
+// different request is rejected with 422.
+func withIdempotency(service IdempotencyService, mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if r.Method != http.MethodPost || key == "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		if _, route := mux.Handler(r); secretRoutes[route] {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKey {
+			writeError(w, r, core.NewErrBadRequest())
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, core.NewErrBadRequest())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		client := requestClient(r)
+		stored, err := service.Begin(r.Context(), client, key, requestHash(r, body))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w}
+		mux.ServeHTTP(recorder, r)
+
+		response := &core.StoredResponse{
+			StatusCode:  recorder.statusCode(),
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := service.Finish(context.WithoutCancel(r.Context()), client, key, response); err != nil {
+			logging.From(r.Context()).WithError(err).Warn("could not store idempotent response")
+		}
+	})
+}
+
+// remoteIP returns the address of the client of a request.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestClient identifies the client of a request by its authenticated
+// API key, or else by remote IP. Headers a client can change at will are
+// never used.
+func requestClient(r *http.Request) string {
+	if key := core.APIKeyFrom(r.Context()); key != nil {
+		return "key:" + strconv.Itoa(key.Id)
+	}
+	return "ip:" + remoteIP(r)
+}
+
+// requestHash identifies a request by method, URL and body.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(statusCode int) {
+	if recorder.status == 0 {
+		recorder.status = statusCode
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *responseRecorder) Write(b []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	recorder.body.Write(b)
+	return recorder.ResponseWriter.Write(b)
+}
+
+func (recorder *responseRecorder) statusCode() int {
+	if recorder.status == 0 {
+		return http.StatusOK
+	}
+	return recorder.status
+}
@@ -0,0 +1,42 @@
+package transport
+
+import (
+	"net/http"
+	"testing"
+
+	"filmoteka/internal/core"
+)
+
+func TestIdempotency(t *testing.T) {
+
+	server := newTestServer(t)
+	martin := `{"name":"Martin Freeman","sex":77,"bd":"1971-09-08"}`
+	andrew := `{"name":"Andrew Scott","sex":77,"bd":"1976-10-21"}`
+
+	first := server.do(t, http.MethodPost, "/actors", martin, "Idempotency-Key", "k").expect(t, http.StatusCreated)
+
+	retry := server.do(t, http.MethodPost, "/actors", martin, "Idempotency-Key", "k").expect(t, http.StatusCreated)
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry was not marked as replayed")
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("replayed body = %s, want %s", retry.Body, first.Body)
+	}
+
+	server.do(t, http.MethodPost, "/actors", andrew, "Idempotency-Key", "k").expectError(t, core.NewErrIdempotencyKeyReused())
+
+	// Another client picking the same key gets its own request through.
+	r := newRequest(http.MethodPost, "/actors", andrew, "Idempotency-Key", "k")
+	r.RemoteAddr = "198.51.100.7:4321"
+	other := server.serve(r).expect(t, http.StatusCreated)
+	if other.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("request of another client was replayed")
+	}
+
+	// Only the first request created Martin Freeman.
+	var actors []core.Actor
+	server.do(t, http.MethodGet, "/actors", "").expect(t, http.StatusOK).decode(t, &actors)
+	if len(actors) != 2 {
+		t.Errorf("actors = %+v, want Martin Freeman and Andrew Scott", actors)
+	}
+}
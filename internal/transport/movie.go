@@ -4,18 +4,17 @@ import (
 	"context"
 	"filmoteka/internal/core"
 	"net/http"
-
-	"encoding/json"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type MovieService interface {
 	CreateMovie(ctx context.Context, movie *core.Movie) error
-	DeleteMovie() error
-	UpdateMovie(ctx context.Context, id int, columnName string, newValue interface{}) error
-	AddActors(ctx context.Context, id int, actors []string) error
-	DeleteActors(ctx context.Context, id int, actors []string) error
+	DeleteMovie(ctx context.Context, id int, version int) error
+	RestoreMovie(ctx context.Context, id int) error
+	UpdateMovie(ctx context.Context, id int, version int, columnName string, newValue interface{}) error
+	AddActors(ctx context.Context, id int, cast []core.CastMember) error
+	DeleteActors(ctx context.Context, id int, cast []core.CastMember) error
+	GetMovie(ctx context.Context, id int) (*core.MovieDetail, error)
+	GetAll(ctx context.Context, sorting string) ([]*core.Movie, error)
 	SearchMovie(ctx context.Context, search string) ([]*core.Movie, error)
 }
 
@@ -32,25 +31,223 @@ type KeyMovie struct{}
 func (handler *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
 
 	movie := &core.Movie{}
-	log.Info("Parsing movie from request")
+	if err := decodeBody(r, movie); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.CreateMovie(r.Context(), movie); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, movie)
+}
+
+// movieIncludes lists the relations GET /movies/{id} can expand. Movies
+// have no genres yet, the expansion is always empty.
+var movieIncludes = map[string]bool{"actors": true, "genres": true}
+
+// movieResponse controls which relations of a movie are rendered.
+type movieResponse struct {
+	*core.MovieDetail
+	Actors *[]*core.CastActor `json:"actors,omitempty"`
+	Genres *[]string          `json:"genres,omitempty"`
+}
+
+func (handler *MovieHandler) GetMovie(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	include, err := parseInclude(r, movieIncludes, "actors")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	movie, err := handler.movieService.GetMovie(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	detail := ""
+	if include["actors"] {
+		detail = "actors:" + movie.CastVersion
+	}
+	if include["genres"] {
+		detail += ";genres"
+	}
 
-	d := json.NewDecoder(r.Body)
-	err := d.Decode(&movie)
+	etag := makeETag(movie.Version, detail)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	response := movieResponse{MovieDetail: movie}
+	if include["actors"] {
+		response.Actors = &movie.Actors
+	}
+	if include["genres"] {
+		response.Genres = &[]string{}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
 
+func (handler *MovieHandler) GetMovies(w http.ResponseWriter, r *http.Request) {
+
+	movies, err := handler.movieService.GetAll(r.Context(), r.URL.Query().Get("sort"))
 	if err != nil {
-		log.Info("Could not parse movie from request")
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		writeError(w, r, err)
+		return
+	}
+
+	if movies == nil {
+		movies = []*core.Movie{}
 	}
 
-	log.Info("Parsed movie - ", movie)
+	writeJSON(w, http.StatusOK, movies)
+}
+
+func (handler *MovieHandler) SearchMovies(w http.ResponseWriter, r *http.Request) {
 
-	err = handler.movieService.CreateMovie(r.Context(), movie)
+	movies, err := handler.movieService.SearchMovie(r.Context(), r.URL.Query().Get("q"))
 	if err != nil {
-		log.Info(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(movie)
+	if movies == nil {
+		movies = []*core.Movie{}
+	}
+
+	writeJSON(w, http.StatusOK, movies)
+}
+
+func (handler *MovieHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r, handler.currentVersion(r, id))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	update := &UpdateRequest{}
+	if err := decodeBody(r, update); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.UpdateMovie(r.Context(), id, version, update.Column, updateValue(update.Value)); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *MovieHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	version, err := ifMatchVersion(r, handler.currentVersion(r, id))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.DeleteMovie(r.Context(), id, version); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *MovieHandler) AddActors(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actors := &ActorsRequest{}
+	if err := decodeBody(r, actors); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.AddActors(r.Context(), id, actors.Actors); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *MovieHandler) DeleteActors(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	actors := &ActorsRequest{}
+	if err := decodeBody(r, actors); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.DeleteActors(r.Context(), id, actors.Actors); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (handler *MovieHandler) RestoreMovie(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.movieService.RestoreMovie(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentVersion looks up the version of the movie for ifMatchVersion.
+func (handler *MovieHandler) currentVersion(r *http.Request, id int) func() (int, error) {
+	return func() (int, error) {
+		movie, err := handler.movieService.GetMovie(r.Context(), id)
+		if err != nil {
+			return 0, err
+		}
+		return movie.Version, nil
+	}
 }
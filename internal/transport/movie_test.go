@@ -0,0 +1,125 @@
+package transport
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"filmoteka/internal/core"
+)
+
+func TestMovieHandler(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		status  int
+		wantErr *core.MyError
+	}{
+		{"create", http.MethodPost, "/movies", `{"title":"Doctor Strange","descr":"d","release":"2016-10-20","rating":8,"actors":[1,2]}`, http.StatusCreated, nil},
+		{"create with the old route", http.MethodPost, "/movie", `{"title":"Doctor Strange","descr":"d","release":"2016-10-20","rating":8,"actors":[]}`, http.StatusCreated, nil},
+		{"create a duplicate title", http.MethodPost, "/movies", `{"title":"Sherlock","descr":"d","release":"2016-10-20","rating":8,"actors":[]}`, 0, core.NewErrMovieAlreadyExists()},
+		{"create with missing actors", http.MethodPost, "/movies", `{"title":"Doctor Strange","descr":"d","release":"2016-10-20","rating":8,"actors":[9,1,7]}`, 0, core.NewErrActorsDoNotExist([]int{7, 9})},
+		{"create from bad json", http.MethodPost, "/movies", `{"title":`, 0, core.NewErrBadRequest()},
+		{"get", http.MethodGet, "/movies/1", "", http.StatusOK, nil},
+		{"get a missing movie", http.MethodGet, "/movies/42", "", 0, core.NewErrMovieDoesNotExist()},
+		{"get with a bad id", http.MethodGet, "/movies/one", "", 0, core.NewErrBadRequest()},
+		{"get with an unknown include", http.MethodGet, "/movies/1?include=crew", "", 0, core.NewErrUnknownInclude("crew")},
+		{"list", http.MethodGet, "/movies?sort=title", "", http.StatusOK, nil},
+		{"list with an unknown sorting", http.MethodGet, "/movies?sort=popularity", "", 0, core.NewErrUnknownSorting()},
+		{"search", http.MethodGet, "/movies/search?q=sher", "", http.StatusOK, nil},
+		{"update", http.MethodPatch, "/movies/1", `{"column":"rating","value":10}`, http.StatusNoContent, nil},
+		{"update with a bad id", http.MethodPatch, "/movies/one", `{"column":"rating","value":10}`, 0, core.NewErrBadRequest()},
+		{"update to a duplicate title", http.MethodPatch, "/movies/1", `{"column":"title","value":"Elementary"}`, 0, core.NewErrMovieAlreadyExists()},
+		{"update an unknown column", http.MethodPatch, "/movies/1", `{"column":"id","value":3}`, 0, core.NewErrUnknownColumn()},
+		{"update a missing movie", http.MethodPatch, "/movies/42", `{"column":"rating","value":10}`, 0, core.NewErrMovieDoesNotExist()},
+		{"delete", http.MethodDelete, "/movies/1", "", http.StatusNoContent, nil},
+		{"delete a missing movie", http.MethodDelete, "/movies/42", "", 0, core.NewErrMovieDoesNotExist()},
+		{"restore a missing movie", http.MethodPost, "/movies/42/restore", "", 0, core.NewErrMovieDoesNotExist()},
+		{"add actors", http.MethodPost, "/movies/1/actors", `{"actors":[{"actor_name":"Martin Freeman","character":"John Watson"}]}`, http.StatusNoContent, nil},
+		{"add linked actors", http.MethodPost, "/movies/1/actors", `{"actors":[{"actor_id":2},{"actor_id":1}]}`, 0, core.NewErrActorsAlreadyLinked([]int{1})},
+		{"add missing actors", http.MethodPost, "/movies/1/actors", `{"actors":[{"actor_id":9}]}`, 0, core.NewErrActorsDoNotExist([]int{9})},
+		{"add unknown names", http.MethodPost, "/movies/1/actors", `{"actors":[{"actor_name":"Nobody"}]}`, 0, core.NewErrActorNamesDoNotExist([]string{"Nobody"})},
+		{"add to a missing movie", http.MethodPost, "/movies/42/actors", `{"actors":[{"actor_id":2}]}`, 0, core.NewErrMovieDoesNotExist()},
+		{"delete actors", http.MethodDelete, "/movies/1/actors", `{"actors":[{"actor_id":1}]}`, http.StatusNoContent, nil},
+		{"delete unlinked actors", http.MethodDelete, "/movies/1/actors", `{"actors":[{"actor_id":2}]}`, 0, core.NewErrActorsNotLinked([]int{2})},
+		{"delete nobody", http.MethodDelete, "/movies/1/actors", `{"actors":[]}`, 0, core.NewErrBadRequest()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer(t)
+			server.seed(t)
+
+			response := server.do(t, test.method, test.path, test.body)
+			if test.wantErr != nil {
+				response.expectError(t, test.wantErr)
+				return
+			}
+			response.expect(t, test.status)
+		})
+	}
+}
+
+func TestMovieHandlerGet(t *testing.T) {
+
+	server := newTestServer(t)
+	server.seed(t)
+
+	var movie struct {
+		core.MovieDetail
+		Genres *[]string `json:"genres"`
+	}
+	response := server.do(t, http.MethodGet, "/movies/1?include=actors,genres", "").expect(t, http.StatusOK)
+	response.decode(t, &movie)
+
+	if movie.Title != "Sherlock" || len(movie.Actors) != 1 || movie.Actors[0].Name != "Benedict Cumberbatch" {
+		t.Errorf("movie = %+v", movie.MovieDetail)
+	}
+	if movie.Genres == nil || len(*movie.Genres) != 0 {
+		t.Errorf("genres = %v, want an empty list", movie.Genres)
+	}
+
+	var bare core.MovieDetail
+	server.do(t, http.MethodGet, "/movies/1?include=", "").expect(t, http.StatusOK).decode(t, &bare)
+	if bare.Actors != nil {
+		t.Errorf("cast = %+v, want it left out", bare.Actors)
+	}
+
+	etag := response.Header().Get("ETag")
+	server.do(t, http.MethodGet, "/movies/1?include=actors,genres", "", "If-None-Match", etag).expect(t, http.StatusNotModified)
+
+	// The ETag changes with the cast, so a stale one no longer matches.
+	server.do(t, http.MethodPatch, "/actors/1", `{"column":"name","value":"Benedict Timothy Carlton Cumberbatch"}`).expect(t, http.StatusNoContent)
+	server.do(t, http.MethodGet, "/movies/1?include=actors,genres", "", "If-None-Match", etag).expect(t, http.StatusOK)
+}
+
+// Deleting a movie takes it out of the movies of its actors until it is
+// restored.
+func TestMovieHandlerCascade(t *testing.T) {
+
+	server := newTestServer(t)
+	server.seed(t)
+
+	server.do(t, http.MethodDelete, "/movies/1", "").expect(t, http.StatusNoContent)
+	server.do(t, http.MethodGet, "/movies/1", "").expectError(t, core.NewErrMovieDoesNotExist())
+
+	var actor core.Actor
+	server.do(t, http.MethodGet, "/actors/1", "").expect(t, http.StatusOK).decode(t, &actor)
+	if len(actor.Movies) != 0 {
+		t.Errorf("actor movies = %v, want the deleted movie left out", actor.Movies)
+	}
+
+	// The title is free while the movie is deleted.
+	server.do(t, http.MethodPost, "/movies", `{"title":"Sherlock","descr":"d","release":"2016-10-20","rating":8,"actors":[]}`).expect(t, http.StatusCreated)
+	server.do(t, http.MethodPost, "/movies/1/restore", "").expectError(t, core.NewErrMovieAlreadyExists())
+	server.do(t, http.MethodDelete, "/movies/3", "").expect(t, http.StatusNoContent)
+	server.do(t, http.MethodPost, "/movies/1/restore", "").expect(t, http.StatusNoContent)
+
+	server.do(t, http.MethodGet, "/actors/1", "").expect(t, http.StatusOK).decode(t, &actor)
+	if !reflect.DeepEqual(actor.Movies, []int{1}) {
+		t.Errorf("actor movies = %v, want the restored movie back", actor.Movies)
+	}
+}
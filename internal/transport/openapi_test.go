@@ -0,0 +1,528 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"filmoteka/api"
+	"filmoteka/internal/core"
+	"filmoteka/internal/service"
+)
+
+// openAPISpec is the part of the OpenAPI document the contract test checks
+// responses against.
+type openAPISpec struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas   map[string]map[string]interface{} `json:"schemas"`
+		Responses map[string]specResponse           `json:"responses"`
+	} `json:"components"`
+}
+
+type specOperation struct {
+	RequestBody *struct {
+		Content map[string]specMedia `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]specResponse `json:"responses"`
+}
+
+type specResponse struct {
+	Ref     string                     `json:"$ref"`
+	Headers map[string]json.RawMessage `json:"headers"`
+	Content map[string]specMedia       `json:"content"`
+}
+
+type specMedia struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+func loadOpenAPI(t *testing.T) *openAPISpec {
+	t.Helper()
+
+	spec := &openAPISpec{}
+	if err := json.Unmarshal(api.OpenAPI, spec); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	return spec
+}
+
+// operations lists the operations of the spec as "METHOD /path".
+func (spec *openAPISpec) operations() []string {
+	var operations []string
+	for path, item := range spec.Paths {
+		for method := range item {
+			if method != "parameters" {
+				operations = append(operations, strings.ToUpper(method)+" "+path)
+			}
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func (spec *openAPISpec) operation(t *testing.T, operation string) *specOperation {
+	t.Helper()
+
+	method, path, _ := strings.Cut(operation, " ")
+	raw, ok := spec.Paths[path][strings.ToLower(method)]
+	if !ok {
+		t.Fatalf("%s is not in the spec", operation)
+	}
+
+	op := &specOperation{}
+	if err := json.Unmarshal(raw, op); err != nil {
+		t.Fatalf("%s: %v", operation, err)
+	}
+	return op
+}
+
+func (spec *openAPISpec) response(response specResponse) specResponse {
+	if name, ok := strings.CutPrefix(response.Ref, "#/components/responses/"); ok {
+		return spec.Components.Responses[name]
+	}
+	return response
+}
+
+// validate returns where value, as decoded from JSON, breaks schema.
+func (spec *openAPISpec) validate(schema map[string]interface{}, value interface{}, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		name, _ := strings.CutPrefix(ref, "#/components/schemas/")
+		resolved, ok := spec.Components.Schemas[name]
+		if !ok {
+			return []string{at + ": unknown schema " + ref}
+		}
+		return spec.validate(resolved, value, at)
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || schema["type"] == nil && schema["oneOf"] == nil {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+
+	var problems []string
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matches := 0
+		for _, candidate := range oneOf {
+			if len(spec.validate(withType(candidate.(map[string]interface{}), schema), value, at)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			problems = append(problems, fmt.Sprintf("%s: %v matches %d of oneOf", at, value, matches))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		problems = append(problems, fmt.Sprintf("%s: %v is not one of %v", at, value, enum))
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %v is not an object", at, value))
+		}
+		problems = append(problems, spec.validateObject(schema, object, at)...)
+
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %v is not an array", at, value))
+		}
+		if min, ok := schema["minItems"].(float64); ok && float64(len(array)) < min {
+			problems = append(problems, fmt.Sprintf("%s: %d items, want at least %v", at, len(array), min))
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(array)) > max {
+			problems = append(problems, fmt.Sprintf("%s: %d items, want at most %v", at, len(array), max))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range array {
+				problems = append(problems, spec.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s: %v is not a string", at, value))
+		}
+		if min, ok := schema["minLength"].(float64); ok && float64(len([]rune(s))) < min {
+			problems = append(problems, fmt.Sprintf("%s: %q is shorter than %v", at, s, min))
+		}
+		if max, ok := schema["maxLength"].(float64); ok && float64(len([]rune(s))) > max {
+			problems = append(problems, fmt.Sprintf("%s: %q is longer than %v", at, s, max))
+		}
+		if err := checkFormat(schema["format"], s); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", at, err))
+		}
+
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok || schema["type"] == "integer" && n != float64(int64(n)) {
+			return append(problems, fmt.Sprintf("%s: %v is not an %s", at, value, schema["type"]))
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			problems = append(problems, fmt.Sprintf("%s: %v is less than %v", at, n, min))
+		}
+		if max, ok := schema["maximum"].(float64); ok && n > max {
+			problems = append(problems, fmt.Sprintf("%s: %v is more than %v", at, n, max))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s: %v is not a boolean", at, value))
+		}
+	}
+
+	return problems
+}
+
+// validateObject checks the required and documented properties of object.
+// Objects whose schema lists properties may not have others.
+func (spec *openAPISpec) validateObject(schema, object map[string]interface{}, at string) []string {
+	var problems []string
+
+	required, _ := schema["required"].([]interface{})
+	for _, name := range required {
+		if _, ok := object[name.(string)]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s is required", at, name))
+		}
+	}
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return problems
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s is not documented", at, name))
+			continue
+		}
+		problems = append(problems, spec.validate(property, object[name], at+"."+name)...)
+	}
+	return problems
+}
+
+// withType lets a oneOf alternative that only lists required properties
+// inherit the type and properties of the schema it belongs to.
+func withType(candidate, parent map[string]interface{}) map[string]interface{} {
+	if candidate["type"] != nil || candidate["$ref"] != nil {
+		return candidate
+	}
+
+	merged := map[string]interface{}{"type": parent["type"], "properties": parent["properties"]}
+	for key, value := range candidate {
+		merged[key] = value
+	}
+	return merged
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkFormat(format interface{}, s string) error {
+	var err error
+	switch format {
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "uri":
+		var u *url.URL
+		if u, err = url.Parse(s); err == nil && !u.IsAbs() {
+			err = errors.New("not an absolute uri")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a %v: %v", s, format, err)
+	}
+	return nil
+}
+
+// contract serves requests and checks them and their responses against the
+// spec, remembering which operations it saw.
+type contract struct {
+	server *testServer
+	spec   *openAPISpec
+	called map[string]bool
+}
+
+// call serves a request to operation, e.g. "GET /movies/{id}", at path.
+func (c *contract) call(t *testing.T, operation, path, body string, headers ...string) testResponse {
+	t.Helper()
+
+	method, _, _ := strings.Cut(operation, " ")
+	return c.check(t, operation, newRequest(method, path, body, headers...))
+}
+
+func (c *contract) check(t *testing.T, operation string, r *http.Request) testResponse {
+	t.Helper()
+
+	op := c.spec.operation(t, operation)
+	c.called[operation] = true
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	response := c.server.serve(r)
+
+	// Requests the server accepts must be documented ones too.
+	if op.RequestBody != nil && response.Code < http.StatusBadRequest {
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			t.Errorf("%s: request body %s: %v", operation, body, err)
+		}
+		for _, problem := range c.spec.validate(op.RequestBody.Content["application/json"].Schema, value, "request") {
+			t.Errorf("%s: %s", operation, problem)
+		}
+	}
+
+	documented, ok := op.Responses[strconv.Itoa(response.Code)]
+	if !ok {
+		t.Errorf("%s: status %d is not documented, body %s", operation, response.Code, response.Body)
+		return response
+	}
+	documented = c.spec.response(documented)
+
+	for name := range documented.Headers {
+		if response.Header().Get(name) == "" {
+			t.Errorf("%s: %d response lacks the %s header", operation, response.Code, name)
+		}
+	}
+
+	if len(documented.Content) == 0 {
+		if response.Body.Len() != 0 {
+			t.Errorf("%s: %d response has a body %s, want none", operation, response.Code, response.Body)
+		}
+		return response
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header().Get("Content-Type"))
+	media, ok := documented.Content[mediaType]
+	if !ok {
+		t.Errorf("%s: %d response has the undocumented content type %q", operation, response.Code, mediaType)
+		return response
+	}
+
+	for _, value := range c.bodyValues(t, operation, mediaType, response) {
+		for _, problem := range c.spec.validate(media.Schema, value, "response") {
+			t.Errorf("%s: %d %s", operation, response.Code, problem)
+		}
+	}
+	return response
+}
+
+// bodyValues decodes a JSON body, or the data of every event of a stream.
+func (c *contract) bodyValues(t *testing.T, operation, mediaType string, response testResponse) []interface{} {
+	t.Helper()
+
+	var data []string
+	if mediaType == "text/event-stream" {
+		scanner := bufio.NewScanner(strings.NewReader(response.Body.String()))
+		for scanner.Scan() {
+			if line, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				data = append(data, line)
+			}
+		}
+	} else {
+		data = []string{response.Body.String()}
+	}
+
+	values := make([]interface{}, 0, len(data))
+	for _, raw := range data {
+		var value interface{}
+		if err := json.Unmarshal([]byte(raw), &value); err != nil {
+			t.Errorf("%s: body %s: %v", operation, raw, err)
+			continue
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// failingTransport fails every request, webhooks go dead without reaching
+// the network.
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func issueKey(t *testing.T, server *testServer, name string, scopes ...string) []string {
+	t.Helper()
+
+	key := &core.APIKey{Name: name, Scopes: scopes}
+	if err := server.apiKeys.IssueAPIKey(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	return []string{"Authorization", apiKeyScheme + " " + key.Key}
+}
+
+// TestOpenAPIContract calls every operation of api.OpenAPI and checks that
+// the status codes and bodies the handlers answer with, errors included,
+// are the documented ones.
+func TestOpenAPIContract(t *testing.T) {
+
+	spec := loadOpenAPI(t)
+	server := newTestServer(t)
+	c := &contract{server: server, spec: spec, called: map[string]bool{}}
+
+	admin := issueKey(t, server, "admin", core.ScopeAdmin)
+	actorReader := issueKey(t, server, "actors", core.ScopeActorsRead)
+	invalid := []string{"Authorization", apiKeyScheme + " not-a-key"}
+
+	// Actors
+	c.call(t, "POST /actors", "/actors", `{"name":"Benedict Cumberbatch","sex":77,"bd":"1976-07-19"}`).expect(t, http.StatusCreated)
+	c.call(t, "POST /actors", "/actors", `{"name":"Martin Freeman","sex":77,"bd":"1971-09-08"}`, "Idempotency-Key", "martin").expect(t, http.StatusCreated)
+	c.call(t, "POST /actors", "/actors", `{"name":"Andrew Scott","sex":77,"bd":"1976-10-21"}`, "Idempotency-Key", "martin").expect(t, http.StatusUnprocessableEntity)
+	c.call(t, "POST /actors", "/actors", `{"name":"Martin Freeman","sex":77,"bd":"1971-09-08"}`).expect(t, http.StatusConflict)
+	c.call(t, "POST /actors", "/actors", `{"name":`).expect(t, http.StatusBadRequest)
+	c.call(t, "POST /actors", "/actors", `{"name":"Una Stubbs","sex":70,"bd":"1937-05-01"}`, actorReader...).expect(t, http.StatusForbidden)
+	c.call(t, "POST /actors", "/actors", `{"name":"Una Stubbs","sex":70,"bd":"1937-05-01"}`, invalid...).expect(t, http.StatusUnauthorized)
+	c.call(t, "GET /actors", "/actors", "").expect(t, http.StatusOK)
+
+	etag := c.call(t, "GET /actors/{id}", "/actors/1", "").expect(t, http.StatusOK).Header().Get("ETag")
+	c.call(t, "GET /actors/{id}", "/actors/1", "", "If-None-Match", etag).expect(t, http.StatusNotModified)
+	c.call(t, "GET /actors/{id}", "/actors/42", "").expect(t, http.StatusNotFound)
+	c.call(t, "GET /actors/{id}", "/actors/one", "").expect(t, http.StatusBadRequest)
+
+	c.call(t, "PATCH /actors/{id}", "/actors/2", `{"column":"name","value":"Martin John Freeman"}`, "If-Match", `"1"`).expect(t, http.StatusNoContent)
+	c.call(t, "PATCH /actors/{id}", "/actors/2", `{"column":"name","value":"Martin Freeman"}`, "If-Match", `"1"`).expect(t, http.StatusPreconditionFailed)
+	c.call(t, "PATCH /actors/{id}", "/actors/2", `{"column":"movies","value":"1"}`).expect(t, http.StatusBadRequest)
+	c.call(t, "PATCH /actors/{id}", "/actors/42", `{"column":"sex","value":"F"}`).expect(t, http.StatusNotFound)
+
+	// Movies
+	c.call(t, "POST /movies", "/movies", `{"title":"Sherlock","descr":"A detective","release":"2010-07-25","rating":9,"actors":[1]}`).expect(t, http.StatusCreated)
+	c.call(t, "POST /movie", "/movie", `{"title":"Elementary","descr":"Another one","release":"2012-09-27","rating":7,"actors":[]}`).expect(t, http.StatusCreated)
+	c.call(t, "POST /movies", "/movies", `{"title":"Sherlock","descr":"A detective","release":"2010-07-25","rating":9,"actors":[]}`).expect(t, http.StatusConflict)
+	c.call(t, "POST /movies", "/movies", `{"title":"The Hobbit","descr":"A journey","release":"2012-12-14","rating":8,"actors":[9]}`).expect(t, http.StatusNotFound)
+	c.call(t, "POST /movie", "/movie", `[]`).expect(t, http.StatusBadRequest)
+
+	c.call(t, "GET /movies", "/movies?sort=title", "").expect(t, http.StatusOK)
+	c.call(t, "GET /movies", "/movies?sort=length", "").expect(t, http.StatusBadRequest)
+	etag = c.call(t, "GET /movies/{id}", "/movies/1?include=actors", "").expect(t, http.StatusOK).Header().Get("ETag")
+	c.call(t, "GET /movies/{id}", "/movies/1?include=actors", "", "If-None-Match", etag).expect(t, http.StatusNotModified)
+	c.call(t, "GET /movies/{id}", "/movies/2", "").expect(t, http.StatusOK)
+	c.call(t, "GET /movies/{id}", "/movies/1?include=actors,genres", "").expect(t, http.StatusOK)
+	c.call(t, "GET /movies/{id}", "/movies/1?include=awards", "").expect(t, http.StatusBadRequest)
+	c.call(t, "GET /movies/{id}", "/movies/42", "").expect(t, http.StatusNotFound)
+	c.call(t, "GET /movies/search", "/movies/search?q=Sher", "").expect(t, http.StatusOK)
+	c.call(t, "GET /movies", "/movies", "", actorReader...).expect(t, http.StatusForbidden)
+
+	c.call(t, "POST /movies/{id}/actors", "/movies/1/actors", `{"actors":[{"actor_name":"Martin John Freeman","character":"John Watson","billing":2}]}`).expect(t, http.StatusNoContent)
+	c.call(t, "POST /movies/{id}/actors", "/movies/1/actors", `{"actors":[{"actor_id":2}]}`).expect(t, http.StatusConflict)
+	c.call(t, "POST /movies/{id}/actors", "/movies/1/actors", `{"actors":[{"actor_id":9},{"actor_id":7}]}`).expect(t, http.StatusNotFound)
+	c.call(t, "POST /movies/{id}/actors", "/movies/one/actors", `{"actors":[{"actor_id":9}]}`).expect(t, http.StatusBadRequest)
+	c.call(t, "DELETE /movies/{id}/actors", "/movies/1/actors", `{"actors":[{"actor_id":2}]}`).expect(t, http.StatusNoContent)
+	c.call(t, "DELETE /movies/{id}/actors", "/movies/1/actors", `{"actors":[{"actor_id":2}]}`).expect(t, http.StatusNotFound)
+
+	c.call(t, "PATCH /movies/{id}", "/movies/2", `{"column":"rating","value":8}`).expect(t, http.StatusNoContent)
+	c.call(t, "PATCH /movies/{id}", "/movies/2", `{"column":"rating","value":6}`, "If-Match", `"1"`).expect(t, http.StatusPreconditionFailed)
+	c.call(t, "PATCH /movies/{id}", "/movies/2", `{"column":"actors","value":"1"}`).expect(t, http.StatusBadRequest)
+	c.call(t, "PATCH /movies/{id}", "/movies/42", `{"column":"rating","value":8}`).expect(t, http.StatusNotFound)
+
+	c.call(t, "DELETE /movies/{id}", "/movies/2", "", "If-Match", `"1"`).expect(t, http.StatusPreconditionFailed)
+	c.call(t, "DELETE /movies/{id}", "/movies/2", "").expect(t, http.StatusNoContent)
+	c.call(t, "DELETE /movies/{id}", "/movies/2", "").expect(t, http.StatusNotFound)
+	c.call(t, "POST /movies/{id}/restore", "/movies/2/restore", "").expect(t, http.StatusNoContent)
+	c.call(t, "POST /movies/{id}/restore", "/movies/42/restore", "").expect(t, http.StatusNotFound)
+
+	c.call(t, "DELETE /actors/{id}", "/actors/2", "").expect(t, http.StatusNoContent)
+	c.call(t, "DELETE /actors/{id}", "/actors/2", "").expect(t, http.StatusNotFound)
+	c.call(t, "POST /actors/{id}/restore", "/actors/2/restore", "").expect(t, http.StatusNoContent)
+	c.call(t, "POST /actors/{id}/restore", "/actors/42/restore", "").expect(t, http.StatusNotFound)
+	c.call(t, "DELETE /actors/{id}", "/actors/two", "").expect(t, http.StatusBadRequest)
+
+	// Batches
+	c.call(t, "POST /batch", "/batch", `{"operations":[{"op":"create_actor","ref":"andrew","actor":{"name":"Andrew Scott","sex":77,"bd":"1976-10-21"}},{"op":"link","id":1,"actors":[{"actor_id":"andrew","character":"Jim Moriarty","billing":3}]}]}`).expect(t, http.StatusOK)
+	c.call(t, "POST /batch", "/batch", `{"operations":[{"op":"update_movie","id":42,"column":"rating","value":5}]}`).expect(t, http.StatusNotFound)
+	c.call(t, "POST /batch", "/batch", `{"operations":[{"op":"update_movie","id":1,"version":1,"column":"rating","value":5}]}`).expect(t, http.StatusPreconditionFailed)
+	c.call(t, "POST /batch", "/batch", `{"operations":[{"op":"create_actor","actor":{"name":"Andrew Scott","sex":77,"bd":"1976-10-21"}}]}`).expect(t, http.StatusConflict)
+	c.call(t, "POST /batch", "/batch", `{"operations":[{"op":"link","id":"sherlock","actors":[{"actor_id":1}]}]}`).expect(t, http.StatusBadRequest)
+
+	// Audit log and change feed
+	c.call(t, "GET /audit", "/audit?entity=movie&limit=2", "", admin...).expect(t, http.StatusOK)
+	c.call(t, "GET /audit", "/audit?after=0", "", admin...).expect(t, http.StatusOK)
+	c.call(t, "GET /audit", "/audit?limit=ten", "", admin...).expect(t, http.StatusBadRequest)
+	c.call(t, "GET /audit", "/audit", "").expect(t, http.StatusUnauthorized)
+	c.call(t, "GET /audit", "/audit", "", actorReader...).expect(t, http.StatusForbidden)
+
+	r := newRequest(http.MethodGet, "/events", "")
+	ctx, cancel := context.WithTimeout(r.Context(), 100*time.Millisecond)
+	defer cancel()
+	if events := c.check(t, "GET /events", r.WithContext(ctx)).expect(t, http.StatusOK); !strings.Contains(events.Body.String(), "event: movie.created") {
+		t.Errorf("events = %s, want the movies created", events.Body)
+	}
+	c.call(t, "GET /events", "/events?entity=director", "").expect(t, http.StatusBadRequest)
+	c.call(t, "GET /events", "/events?entity=movie", "", actorReader...).expect(t, http.StatusForbidden)
+
+	// Webhooks
+	c.call(t, "POST /admin/webhooks", "/admin/webhooks", `{"url":"https://203.0.113.10/filmoteka","events":["movie.updated"]}`, admin...).expect(t, http.StatusCreated)
+	c.call(t, "POST /admin/webhooks", "/admin/webhooks", `{"url":"http://127.0.0.1/filmoteka","events":["movie.updated"]}`, admin...).expect(t, http.StatusBadRequest)
+	c.call(t, "POST /admin/webhooks", "/admin/webhooks", `{"url":"https://203.0.113.10/filmoteka","events":["movie.updated"]}`).expect(t, http.StatusUnauthorized)
+	c.call(t, "GET /admin/webhooks", "/admin/webhooks", "", admin...).expect(t, http.StatusOK)
+	c.call(t, "GET /admin/webhooks", "/admin/webhooks", "", actorReader...).expect(t, http.StatusForbidden)
+
+	c.call(t, "PATCH /movies/{id}", "/movies/1", `{"column":"descr","value":"A consulting detective"}`).expect(t, http.StatusNoContent)
+	dispatcher := service.NewWebhookDispatcher(server.webhooks, &http.Client{Transport: failingTransport{}}, time.Minute, time.Second, time.Minute, 1)
+	if err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c.call(t, "GET /admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries?status=dead&limit=10", "", admin...).expect(t, http.StatusOK)
+	c.call(t, "GET /admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries?status=lost", "", admin...).expect(t, http.StatusBadRequest)
+	c.call(t, "POST /admin/webhooks/{id}/deliveries/{delivery}/retry", "/admin/webhooks/1/deliveries/1/retry", "", admin...).expect(t, http.StatusNoContent)
+	c.call(t, "POST /admin/webhooks/{id}/deliveries/{delivery}/retry", "/admin/webhooks/1/deliveries/1/retry", "", admin...).expect(t, http.StatusConflict)
+	c.call(t, "POST /admin/webhooks/{id}/deliveries/{delivery}/retry", "/admin/webhooks/1/deliveries/42/retry", "", admin...).expect(t, http.StatusNotFound)
+	c.call(t, "POST /admin/webhooks/{id}/deliveries/{delivery}/retry", "/admin/webhooks/1/deliveries/first/retry", "", admin...).expect(t, http.StatusBadRequest)
+	c.call(t, "GET /admin/webhooks/{id}/deliveries", "/admin/webhooks/1/deliveries", "", admin...).expect(t, http.StatusOK)
+
+	c.call(t, "DELETE /admin/webhooks/{id}", "/admin/webhooks/1", "", admin...).expect(t, http.StatusNoContent)
+	c.call(t, "DELETE /admin/webhooks/{id}", "/admin/webhooks/1", "", admin...).expect(t, http.StatusNotFound)
+
+	// API keys
+	c.call(t, "POST /admin/apikeys", "/admin/apikeys", `{"name":"catalogue","scopes":["movies:read","movies:write"],"expires_at":"2030-01-01T00:00:00Z"}`, admin...).expect(t, http.StatusCreated)
+	c.call(t, "POST /admin/apikeys", "/admin/apikeys", `{"name":"catalogue","scopes":["movies:delete"]}`, admin...).expect(t, http.StatusBadRequest)
+	c.call(t, "GET /admin/apikeys", "/admin/apikeys", "", admin...).expect(t, http.StatusOK)
+	c.call(t, "GET /admin/apikeys", "/admin/apikeys", "", invalid...).expect(t, http.StatusUnauthorized)
+	c.call(t, "DELETE /admin/apikeys/{id}", "/admin/apikeys/3", "", admin...).expect(t, http.StatusNoContent)
+	c.call(t, "DELETE /admin/apikeys/{id}", "/admin/apikeys/42", "", admin...).expect(t, http.StatusNotFound)
+	c.call(t, "DELETE /admin/apikeys/{id}", "/admin/apikeys/2", "", actorReader...).expect(t, http.StatusForbidden)
+
+	for _, operation := range spec.operations() {
+		if !c.called[operation] {
+			t.Errorf("%s is documented but was not called", operation)
+		}
+	}
+}
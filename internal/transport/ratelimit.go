@@ -0,0 +1,222 @@
+package transport
+
+import (
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	classRead   = "read"
+	classSearch = "search"
+	classWrite  = "write"
+
+	// sweepInterval is how often idle buckets and past quotas are dropped.
+	sweepInterval = time.Minute
+)
+
+// RateLimiter keeps a token bucket per client and route class, and counts
+// the requests of API keys against their daily quota. State is kept per
+// instance.
+type RateLimiter struct {
+	limits     map[string]config.Limit
+	dailyQuota int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	quotas    map[string]*quota
+	lastSweep time.Time
+}
+
+type bucket struct {
+	limit  config.Limit
+	tokens float64
+	last   time.Time
+}
+
+type quota struct {
+	day   string
+	count int
+}
+
+// rateDecision is what a request learns about its limit.
+type rateDecision struct {
+	err       *core.MyError
+	limit     int
+	remaining int
+	// reset is when the limit is fully available again, retry when the
+	// request may be retried.
+	reset time.Duration
+	retry time.Duration
+}
+
+func NewRateLimiter(cfg *config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limits:     map[string]config.Limit{classRead: cfg.Read, classSearch: cfg.Search, classWrite: cfg.Write},
+		dailyQuota: cfg.DailyQuota,
+		buckets:    map[string]*bucket{},
+		quotas:     map[string]*quota{},
+		lastSweep:  time.Now(),
+	}
+}
+
+// allow takes a token from the bucket of client for class, or with take
+// off only checks that one is left.
+func (limiter *RateLimiter) allow(client, class string, apiKey, take bool, now time.Time) rateDecision {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if now.Sub(limiter.lastSweep) > sweepInterval {
+		limiter.sweep(now)
+	}
+
+	var q *quota
+	if apiKey && limiter.dailyQuota > 0 {
+		day := now.UTC().Format(time.DateOnly)
+		if q = limiter.quotas[client]; q == nil || q.day != day {
+			q = &quota{day: day}
+			limiter.quotas[client] = q
+		}
+
+		if q.count >= limiter.dailyQuota {
+			midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			return rateDecision{err: core.NewErrQuotaExceeded(), limit: limiter.dailyQuota, reset: midnight.Sub(now), retry: midnight.Sub(now)}
+		}
+	}
+
+	limit := limiter.limits[class]
+	key := class + "|" + client
+	b := limiter.buckets[key]
+	if b == nil {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		limiter.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+
+	decision := rateDecision{limit: limit.Burst}
+	if b.tokens >= 1 {
+		if take {
+			b.tokens--
+			if q != nil {
+				q.count++
+			}
+		}
+	} else {
+		decision.err = core.NewErrRateLimited()
+		decision.retry = seconds((1 - b.tokens) / limit.Rate)
+	}
+	decision.remaining = int(b.tokens)
+	decision.reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return decision
+}
+
+// sweep drops buckets that refilled completely and quotas of past days.
+// Callers must hold the lock.
+func (limiter *RateLimiter) sweep(now time.Time) {
+	for key, b := range limiter.buckets {
+		if now.Sub(b.last).Seconds()*b.limit.Rate+b.tokens >= float64(b.limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+
+	day := now.UTC().Format(time.DateOnly)
+	for key, q := range limiter.quotas {
+		if q.day != day {
+			delete(limiter.quotas, key)
+		}
+	}
+
+	limiter.lastSweep = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// routeClass tells which limit applies to a request.
+func routeClass(r *http.Request) string {
+	switch {
+	case r.Method != http.MethodGet && r.Method != http.MethodHead:
+		return classWrite
+	case r.URL.Path == "/movies/search":
+		return classSearch
+	}
+	return classRead
+}
+
+// withRateLimit limits requests before they are authenticated, so that
+// floods of invalid keys are throttled before they reach the database.
+// Requests without an API key draw on the bucket of their remote IP.
+// Requests with one are refused while that bucket is empty and draw on it
+// when the key is rejected, valid keys are limited by withKeyRateLimit.
+// A nil limiter lets everything through.
+func withRateLimit(limiter *RateLimiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, class := "ip:"+remoteIP(r), routeClass(r)
+
+		if _, ok := presentedAPIKey(r); !ok {
+			if limiter.limit(w, r, client, class, false, true) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		if !limiter.limit(w, r, client, class, false, false) {
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.statusCode() == http.StatusUnauthorized {
+			limiter.allow(client, class, false, true, time.Now())
+		}
+	})
+}
+
+// withKeyRateLimit limits requests made with a valid API key by the bucket
+// and daily quota of the key, wherever they come from.
+func withKeyRateLimit(limiter *RateLimiter, next http.Handler) http.Handler {
+	if limiter == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if core.APIKeyFrom(r.Context()) == nil || limiter.limit(w, r, requestClient(r), routeClass(r), true, true) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// limit applies the decision of allow to a request and reports the state
+// of the limit in RateLimit-* headers. It answers 429 and returns false
+// when the request is over the limit.
+func (limiter *RateLimiter) limit(w http.ResponseWriter, r *http.Request, client, class string, apiKey, take bool) bool {
+	decision := limiter.allow(client, class, apiKey, take, time.Now())
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.reset)))
+
+	if decision.err != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(decision.retry))))
+		writeError(w, r, decision.err)
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
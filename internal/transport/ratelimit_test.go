@@ -0,0 +1,184 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+)
+
+func newTestRateLimiter(dailyQuota int) *RateLimiter {
+	return NewRateLimiter(&config.RateLimitConfig{
+		Read:       config.Limit{Rate: 10, Burst: 10},
+		Search:     config.Limit{Rate: 10, Burst: 10},
+		Write:      config.Limit{Rate: 1, Burst: 2},
+		DailyQuota: dailyQuota,
+	})
+}
+
+func expectDecision(t *testing.T, decision rateDecision, err *core.MyError, remaining int, reset, retry time.Duration) {
+	t.Helper()
+
+	if (decision.err == nil) != (err == nil) || (err != nil && decision.err.Type != err.Type) {
+		t.Fatalf("error = %v, want %v", decision.err, err)
+	}
+	if decision.remaining != remaining || decision.reset != reset || decision.retry != retry {
+		t.Fatalf("remaining %d, reset %s, retry %s, want %d, %s, %s", decision.remaining, decision.reset, decision.retry, remaining, reset, retry)
+	}
+}
+
+func TestRateLimiterBucket(t *testing.T) {
+
+	limiter := newTestRateLimiter(0)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), nil, 1, time.Second, 0)
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), nil, 0, 2*time.Second, 0)
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), core.NewErrRateLimited(), 0, 2*time.Second, time.Second)
+
+	// Half a token refilled is not enough.
+	now = now.Add(500 * time.Millisecond)
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), core.NewErrRateLimited(), 0, 1500*time.Millisecond, 500*time.Millisecond)
+
+	now = now.Add(500 * time.Millisecond)
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), nil, 0, 2*time.Second, 0)
+
+	// The bucket holds no more than burst tokens.
+	now = now.Add(time.Hour)
+	expectDecision(t, limiter.allow("ip:a", classWrite, false, true, now), nil, 1, time.Second, 0)
+
+	// Other classes and clients have buckets of their own.
+	expectDecision(t, limiter.allow("ip:a", classRead, false, true, now), nil, 9, 100*time.Millisecond, 0)
+	expectDecision(t, limiter.allow("ip:b", classWrite, false, true, now), nil, 1, time.Second, 0)
+}
+
+func TestRateLimiterQuota(t *testing.T) {
+
+	limiter := newTestRateLimiter(2)
+	now := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+
+	limiter.allow("key:a", classRead, true, true, now)
+	limiter.allow("key:a", classRead, true, true, now)
+	decision := limiter.allow("key:a", classRead, true, true, now)
+	if decision.err == nil || decision.err.Type != core.NewErrQuotaExceeded().Type {
+		t.Fatalf("error = %v, want the quota exceeded", decision.err)
+	}
+	if decision.limit != 2 || decision.retry != time.Hour || decision.reset != time.Hour {
+		t.Errorf("limit %d, retry %s, reset %s, want 2 until midnight", decision.limit, decision.retry, decision.reset)
+	}
+
+	// Clients without an API key have no quota.
+	for i := 0; i < 3; i++ {
+		if decision := limiter.allow("ip:a", classRead, false, true, now); decision.err != nil {
+			t.Fatalf("request %d of an IP: %v", i, decision.err)
+		}
+	}
+
+	// The quota starts over at midnight UTC.
+	now = now.Add(time.Hour)
+	if decision := limiter.allow("key:a", classRead, true, true, now); decision.err != nil {
+		t.Errorf("next day: %v", decision.err)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+
+	limiter := newTestRateLimiter(5)
+	now := limiter.lastSweep
+
+	limiter.allow("key:a", classWrite, true, true, now)
+	limiter.allow("ip:b", classRead, false, true, now)
+
+	// Within the interval nothing is swept.
+	limiter.allow("ip:c", classRead, false, true, now.Add(sweepInterval/2))
+	if len(limiter.buckets) != 3 {
+		t.Fatalf("%d buckets, want 3", len(limiter.buckets))
+	}
+
+	// A day later every bucket refilled and the quota is from the past.
+	limiter.allow("ip:d", classRead, false, true, now.Add(24*time.Hour))
+	if len(limiter.buckets) != 1 || limiter.buckets[classRead+"|ip:d"] == nil {
+		t.Errorf("buckets = %v, want only the one of the request", limiter.buckets)
+	}
+	if len(limiter.quotas) != 0 {
+		t.Errorf("quotas = %v, want none", limiter.quotas)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+
+	handler := withRateLimit(newTestRateLimiter(0), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := newRequest(http.MethodDelete, "/movies/1", "")
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		remoteAddr string
+		status     int
+		headers    map[string]string
+	}{
+		{"192.0.2.1:1234", http.StatusNoContent, map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "1", "Retry-After": ""}},
+		{"192.0.2.1:1235", http.StatusNoContent, map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "2"}},
+		{"192.0.2.1:1236", http.StatusTooManyRequests, map[string]string{"RateLimit-Remaining": "0", "Retry-After": "1"}},
+		{"198.51.100.7:1234", http.StatusNoContent, map[string]string{"RateLimit-Remaining": "1"}},
+	}
+
+	for _, test := range tests {
+		w := serve(test.remoteAddr)
+		if w.Code != test.status {
+			t.Fatalf("%s: status = %d, want %d", test.remoteAddr, w.Code, test.status)
+		}
+		for name, want := range test.headers {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("%s: %s = %q, want %q", test.remoteAddr, name, got, want)
+			}
+		}
+	}
+}
+
+func TestWithRateLimitRejectedKeys(t *testing.T) {
+
+	limiter := newTestRateLimiter(0)
+	handler := withRateLimit(limiter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != apiKeyScheme+" valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(key string) int {
+		r := newRequest(http.MethodDelete, "/movies/1", "", "Authorization", apiKeyScheme+" "+key)
+		r.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Valid keys leave the bucket of their IP to withKeyRateLimit.
+	for i := 0; i < 3; i++ {
+		if status := serve("valid"); status != http.StatusNoContent {
+			t.Fatalf("request %d with a valid key: status = %d", i, status)
+		}
+	}
+
+	// Rejected keys draw on it until even valid keys are refused.
+	for i := 0; i < 2; i++ {
+		if status := serve("guess"); status != http.StatusUnauthorized {
+			t.Fatalf("guess %d: status = %d, want 401", i, status)
+		}
+	}
+	for _, key := range []string{"guess", "valid"} {
+		if status := serve(key); status != http.StatusTooManyRequests {
+			t.Errorf("%s key after the bucket emptied: status = %d, want 429", key, status)
+		}
+	}
+}
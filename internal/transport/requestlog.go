@@ -0,0 +1,137 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"filmoteka/internal/core"
+	"filmoteka/internal/logging"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestID bounds the ids accepted from clients.
+	maxRequestID = 128
+)
+
+type requestLogKey struct{}
+
+// requestLog is shared by the middlewares of a request, so that the line
+// logged once it is served carries the fields added further in.
+type requestLog struct {
+	entry *log.Entry
+}
+
+// withRequestLog gives every request an id, taken from the X-Request-ID
+// header or generated and echoed back in it, and a logger carrying the id,
+// the route and the trace that the handlers below log through. Each
+// request is logged once served.
+func withRequestLog(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		_, route := mux.Handler(r)
+		fields := log.Fields{"request_id": id, "method": r.Method, "route": route}
+		if route == "" {
+			fields["route"] = r.URL.Path
+		}
+
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("request.id", id))
+		if span.SpanContext().IsValid() {
+			fields["trace_id"] = span.SpanContext().TraceID().String()
+		}
+
+		state := &requestLog{entry: log.WithFields(fields)}
+		ctx := context.WithValue(logging.With(r.Context(), state.entry), requestLogKey{}, state)
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		state.entry.WithFields(log.Fields{
+			"status":   recorder.statusCode(),
+			"duration": time.Since(start),
+		}).Info("http request")
+	})
+}
+
+// withLogFields adds fields to the logger of the request, and to the line
+// logged once it is served.
+func withLogFields(r *http.Request, fields log.Fields) *http.Request {
+	ctx := logging.WithFields(r.Context(), fields)
+	if state, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		state.entry = state.entry.WithFields(fields)
+	}
+	return r.WithContext(ctx)
+}
+
+// withRequestUser records user as the user of the request, both for the
+// audit log and for the logs.
+func withRequestUser(r *http.Request, user string) *http.Request {
+	r = withLogFields(r, log.Fields{"user": user})
+	return r.WithContext(core.WithUser(r.Context(), user))
+}
+
+// validRequestID accepts ids of printable ASCII only, so that they can not
+// forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// statusRecorder remembers the status of a response. Unwrap lets
+// http.ResponseController reach the writer underneath, e.g. to flush
+// events.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	if recorder.status == 0 {
+		recorder.status = statusCode
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *statusRecorder) Write(b []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	return recorder.ResponseWriter.Write(b)
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+func (recorder *statusRecorder) statusCode() int {
+	if recorder.status == 0 {
+		return http.StatusOK
+	}
+	return recorder.status
+}
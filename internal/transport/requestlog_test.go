@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+
+	server := newTestServer(t)
+
+	tests := []struct {
+		name    string
+		headers []string
+		echoed  bool
+	}{
+		{"given", []string{"X-Request-ID", "req-42"}, true},
+		{"missing", nil, false},
+		{"control characters", []string{"X-Request-ID", "req-42\nlevel=error"}, false},
+		{"too long", []string{"X-Request-ID", strings.Repeat("a", maxRequestID+1)}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := server.do(t, http.MethodGet, "/movies", "", test.headers...).expect(t, http.StatusOK)
+
+			id := w.Header().Get("X-Request-ID")
+			if test.echoed && id != test.headers[1] {
+				t.Errorf("X-Request-ID = %q, want %q echoed", id, test.headers[1])
+			}
+			if !test.echoed && (len(id) != 32 || !validRequestID(id)) {
+				t.Errorf("X-Request-ID = %q, want a generated id", id)
+			}
+		})
+	}
+}
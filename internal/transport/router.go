@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"expvar"
+	"filmoteka/api"
+	"filmoteka/internal/core"
+	"net/http"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+func NewRouter(movieHandler *MovieHandler, actorHandler *ActorHandler, auditHandler *AuditHandler, batchHandler *BatchHandler, eventHandler *EventHandler, webhookHandler *WebhookHandler, apiKeyHandler *APIKeyHandler, auth *Authenticator, rateLimiter *RateLimiter, idempotencyService IdempotencyService) http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.Handle("POST /movie", auth.require(movieHandler.CreateMovie, core.ScopeMoviesWrite))
+	mux.Handle("POST /movies", auth.require(movieHandler.CreateMovie, core.ScopeMoviesWrite))
+	mux.Handle("GET /movies", auth.require(movieHandler.GetMovies, core.ScopeMoviesRead))
+	mux.Handle("GET /movies/search", auth.require(movieHandler.SearchMovies, core.ScopeMoviesRead))
+	mux.Handle("GET /movies/{id}", auth.require(movieHandler.GetMovie, core.ScopeMoviesRead))
+	mux.Handle("PATCH /movies/{id}", auth.require(movieHandler.UpdateMovie, core.ScopeMoviesWrite))
+	mux.Handle("DELETE /movies/{id}", auth.require(movieHandler.DeleteMovie, core.ScopeMoviesWrite))
+	mux.Handle("POST /movies/{id}/restore", auth.require(movieHandler.RestoreMovie, core.ScopeMoviesWrite))
+	mux.Handle("POST /movies/{id}/actors", auth.require(movieHandler.AddActors, core.ScopeMoviesWrite))
+	mux.Handle("DELETE /movies/{id}/actors", auth.require(movieHandler.DeleteActors, core.ScopeMoviesWrite))
+
+	mux.Handle("POST /actors", auth.require(actorHandler.CreateActor, core.ScopeActorsWrite))
+	mux.Handle("GET /actors", auth.require(actorHandler.GetActors, core.ScopeActorsRead))
+	mux.Handle("GET /actors/{id}", auth.require(actorHandler.GetActor, core.ScopeActorsRead))
+	mux.Handle("PATCH /actors/{id}", auth.require(actorHandler.UpdateActor, core.ScopeActorsWrite))
+	mux.Handle("DELETE /actors/{id}", auth.require(actorHandler.DeleteActor, core.ScopeActorsWrite))
+	mux.Handle("POST /actors/{id}/restore", auth.require(actorHandler.RestoreActor, core.ScopeActorsWrite))
+
+	mux.Handle("GET /audit", auth.require(auditHandler.GetAudit, core.ScopeAdmin))
+
+	// Batches and events check the scopes of what they touch themselves.
+	mux.Handle("POST /batch", auth.require(batchHandler.RunBatch))
+
+	mux.Handle("GET /events", auth.require(eventHandler.GetEvents))
+
+	mux.Handle("POST /admin/webhooks", auth.require(webhookHandler.CreateWebhook, core.ScopeAdmin))
+	mux.Handle("GET /admin/webhooks", auth.require(webhookHandler.GetWebhooks, core.ScopeAdmin))
+	mux.Handle("DELETE /admin/webhooks/{id}", auth.require(webhookHandler.DeleteWebhook, core.ScopeAdmin))
+	mux.Handle("GET /admin/webhooks/{id}/deliveries", auth.require(webhookHandler.GetDeliveries, core.ScopeAdmin))
+	mux.Handle("POST /admin/webhooks/{id}/deliveries/{delivery}/retry", auth.require(webhookHandler.RetryDelivery, core.ScopeAdmin))
+
+	mux.Handle("POST /admin/apikeys", auth.require(apiKeyHandler.IssueAPIKey, core.ScopeAdmin))
+	mux.Handle("GET /admin/apikeys", auth.require(apiKeyHandler.GetAPIKeys, core.ScopeAdmin))
+	mux.Handle("DELETE /admin/apikeys/{id}", auth.require(apiKeyHandler.RevokeAPIKey, core.ScopeAdmin))
+
+	mux.Handle("GET /debug/vars", auth.require(expvar.Handler().ServeHTTP, core.ScopeAdmin))
+
+	mux.HandleFunc("GET /openapi.json", serveOpenAPI)
+	mux.Handle("GET /docs/", http.StripPrefix("/docs/", docsHandler()))
+	mux.Handle("GET /docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+
+	return withTracing(mux, withRequestLog(mux, withRateLimit(rateLimiter, auth.withAPIKey(withKeyRateLimit(rateLimiter, withIdempotency(idempotencyService, mux))))))
+}
+
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.OpenAPI)
+}
+
+// docsHandler serves the embedded Swagger UI pointed at /openapi.json.
+func docsHandler() http.Handler {
+	files := http.FileServer(http.FS(swaggerFiles.FS))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "swagger-initializer.js" {
+			w.Header().Set("Content-Type", "text/javascript")
+			w.Write([]byte(swaggerInitializer))
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,141 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"filmoteka/internal/config"
+	"filmoteka/internal/core"
+	"filmoteka/internal/repository/memory"
+	"filmoteka/internal/service"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.WarnLevel)
+	os.Exit(m.Run())
+}
+
+// testServer is the router of the server in storage: memory mode.
+type testServer struct {
+	handler  http.Handler
+	apiKeys  *service.APIKeyService
+	webhooks *memory.WebhookRepository
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	store := memory.NewStore()
+	return newTestServerWith(t, store, memory.NewMovieRepository(store))
+}
+
+// newTestServerWith serves movies from movieRepository and the rest from
+// store. Keys are not required, so that tests not about auth go without.
+func newTestServerWith(t *testing.T, store *memory.Store, movieRepository service.MovieRepository) *testServer {
+	t.Helper()
+
+	auditRepository := memory.NewAuditRepository(store)
+	eventService := service.NewEventService(auditRepository)
+	transactor := service.OnCommit(memory.NewTransactor(store), func(context.Context) { eventService.Notify() })
+
+	movieService := service.NewMovieService(movieRepository, transactor)
+	actorService := service.NewActorService(memory.NewActorRepository(store), transactor)
+	apiKeyService := service.NewAPIKeyService(memory.NewAPIKeyRepository())
+	webhookRepository := memory.NewWebhookRepository(store)
+
+	handler := NewRouter(
+		NewMovieHandler(movieService),
+		NewActorHandler(actorService),
+		NewAuditHandler(service.NewAuditService(auditRepository)),
+		NewBatchHandler(service.NewBatchService(movieService, actorService, transactor)),
+		NewEventHandler(eventService),
+		NewWebhookHandler(service.NewWebhookService(webhookRepository)),
+		NewAPIKeyHandler(apiKeyService),
+		NewAuthenticator(apiKeyService, &config.AuthConfig{}),
+		nil,
+		service.NewIdempotencyService(memory.NewIdempotencyRepository(), time.Hour),
+	)
+
+	return &testServer{handler: handler, apiKeys: apiKeyService, webhooks: webhookRepository}
+}
+
+// seed stores the actors Benedict Cumberbatch (1) and Martin Freeman (2),
+// and the movies Sherlock (1), starring the first, and Elementary (2).
+func (server *testServer) seed(t *testing.T) {
+	t.Helper()
+
+	server.do(t, http.MethodPost, "/actors", `{"name":"Benedict Cumberbatch","sex":77,"bd":"1976-07-19"}`).expect(t, http.StatusCreated)
+	server.do(t, http.MethodPost, "/actors", `{"name":"Martin Freeman","sex":77,"bd":"1971-09-08"}`).expect(t, http.StatusCreated)
+	server.do(t, http.MethodPost, "/movies", `{"title":"Sherlock","descr":"A detective","release":"2010-07-25","rating":9,"actors":[1]}`).expect(t, http.StatusCreated)
+	server.do(t, http.MethodPost, "/movies", `{"title":"Elementary","descr":"Another one","release":"2012-09-27","rating":7,"actors":[]}`).expect(t, http.StatusCreated)
+}
+
+type testResponse struct {
+	*httptest.ResponseRecorder
+}
+
+// do serves a request, body is sent as JSON unless empty. headers are
+// name, value pairs.
+func (server *testServer) do(t *testing.T, method, path, body string, headers ...string) testResponse {
+	t.Helper()
+	return server.serve(newRequest(method, path, body, headers...))
+}
+
+func (server *testServer) serve(r *http.Request) testResponse {
+	w := httptest.NewRecorder()
+	server.handler.ServeHTTP(w, r)
+	return testResponse{w}
+}
+
+func newRequest(method, path, body string, headers ...string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	return r
+}
+
+func (response testResponse) expect(t *testing.T, status int) testResponse {
+	t.Helper()
+
+	if response.Code != status {
+		t.Fatalf("status = %d, want %d, body %s", response.Code, status, response.Body)
+	}
+	return response
+}
+
+// expectError checks the status and the ErrorResponse body against want.
+func (response testResponse) expectError(t *testing.T, want *core.MyError) {
+	t.Helper()
+
+	response.expect(t, want.Inf.StatusCode)
+
+	var body ErrorResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body %s: %v", response.Body, err)
+	}
+
+	if body.Type != want.Type || body.Message != want.Inf.Msg || !reflect.DeepEqual(body.Ids, want.Inf.Ids) || !reflect.DeepEqual(body.Names, want.Inf.Names) {
+		t.Fatalf("error = %+v, want %s %q %v %v", body, want.Type, want.Inf.Msg, want.Inf.Ids, want.Inf.Names)
+	}
+}
+
+func (response testResponse) decode(t *testing.T, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(response.Body.Bytes(), v); err != nil {
+		t.Fatalf("body %s: %v", response.Body, err)
+	}
+}
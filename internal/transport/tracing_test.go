@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"filmoteka/internal/core"
+	"filmoteka/internal/pgtest"
+	"filmoteka/internal/repository"
+	"filmoteka/internal/repository/memory"
+	"filmoteka/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a provider sampling every span into a recorder
+// until the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := tracing.NewProvider(recorder, "filmoteka-test", 1)
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func findSpan(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	t.Fatalf("no span %q in %q", name, names)
+	return nil
+}
+
+func assertParent(t *testing.T, child, parent sdktrace.ReadOnlySpan) {
+	t.Helper()
+
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() || child.SpanContext().TraceID() != parent.SpanContext().TraceID() {
+		t.Errorf("span %q is not a child of %q", child.Name(), parent.Name())
+	}
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// assertRequestSpans checks the spans of GET /movies down to the service
+// and returns the service span.
+func assertRequestSpans(t *testing.T, spans []sdktrace.ReadOnlySpan) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	request := findSpan(t, spans, "GET /movies")
+	if request.SpanKind() != trace.SpanKindServer || request.Parent().IsValid() {
+		t.Errorf("request span is a %s span with parent %s, want a root server span", request.SpanKind(), request.Parent().SpanID())
+	}
+	if route := spanAttribute(request, "http.route").AsString(); route != "GET /movies" {
+		t.Errorf("http.route = %q", route)
+	}
+	if status := spanAttribute(request, "http.response.status_code").AsInt64(); status != http.StatusOK {
+		t.Errorf("http.response.status_code = %d", status)
+	}
+
+	service := findSpan(t, spans, "MovieService.GetAll")
+	assertParent(t, service, request)
+	return service
+}
+
+func TestTracing(t *testing.T) {
+
+	t.Run("memory", func(t *testing.T) {
+		recorder := recordSpans(t)
+		server := newTestServer(t)
+		server.seed(t)
+		recorder.Reset()
+
+		server.do(t, http.MethodGet, "/movies", "").expect(t, http.StatusOK)
+
+		spans := recorder.Ended()
+		assertRequestSpans(t, spans)
+		if len(spans) != 2 {
+			t.Errorf("%d spans, want the request and service ones only, the memory store runs no queries", len(spans))
+		}
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		db := pgtest.Start(t)
+		movies := repository.NewMovieRepository(db, nil)
+		for _, movie := range []*core.Movie{
+			{Title: "Sherlock", Descr: "A detective", Release: "2010-07-25", Rating: 9},
+			{Title: "Elementary", Descr: "Another one", Release: "2012-09-27", Rating: 7},
+		} {
+			if err := movies.CreateMovie(context.Background(), movie); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		recorder := recordSpans(t)
+		server := newTestServerWith(t, memory.NewStore(), movies)
+
+		server.do(t, http.MethodGet, "/movies", "").expect(t, http.StatusOK)
+
+		spans := recorder.Ended()
+		service := assertRequestSpans(t, spans)
+
+		query := findSpan(t, spans, "sql SortMoviesByRating")
+		assertParent(t, query, service)
+		if name := spanAttribute(query, "db.statement.name").AsString(); name != "SortMoviesByRating" {
+			t.Errorf("db.statement.name = %q", name)
+		}
+		if rows := spanAttribute(query, "db.response.returned_rows").AsInt64(); rows != 2 {
+			t.Errorf("db.response.returned_rows = %d, want 2", rows)
+		}
+	})
+}
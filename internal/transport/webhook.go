@@ -0,0 +1,117 @@
+package transport
+
+import (
+	"context"
+	"filmoteka/internal/core"
+	"net/http"
+	"strconv"
+)
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook *core.Webhook) error
+	ListWebhooks(ctx context.Context) ([]*core.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]*core.WebhookDelivery, error)
+	RetryDelivery(ctx context.Context, webhookID int, id int64) error
+}
+
+type WebhookHandler struct {
+	webhookService WebhookService
+}
+
+func NewWebhookHandler(service WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: service}
+}
+
+// CreateWebhook registers a webhook, the response is the only one that
+// contains its secret.
+func (handler *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+
+	webhook := &core.Webhook{}
+	if err := decodeBody(r, webhook); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.webhookService.CreateWebhook(r.Context(), webhook); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+func (handler *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+
+	webhooks, err := handler.webhookService.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func (handler *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := handler.webhookService.DeleteWebhook(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries lists the latest deliveries of a webhook, filtered by the
+// status query parameter.
+func (handler *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	deliveries, err := handler.webhookService.ListDeliveries(r.Context(), id, r.URL.Query().Get("status"), limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// RetryDelivery sends a dead delivery again.
+func (handler *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	delivery, err := strconv.ParseInt(r.PathValue("delivery"), 10, 64)
+	if err != nil {
+		writeError(w, r, core.NewErrBadRequest())
+		return
+	}
+
+	if err := handler.webhookService.RetryDelivery(r.Context(), id, delivery); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
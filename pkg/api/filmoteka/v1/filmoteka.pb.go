@@ -0,0 +1,1222 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: filmoteka/v1/filmoteka.proto
+
+package filmotekav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Movie struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Descr         string                 `protobuf:"bytes,3,opt,name=descr,proto3" json:"descr,omitempty"`
+	Release       string                 `protobuf:"bytes,4,opt,name=release,proto3" json:"release,omitempty"`
+	Rating        int32                  `protobuf:"varint,5,opt,name=rating,proto3" json:"rating,omitempty"`
+	Actors        []int32                `protobuf:"varint,6,rep,packed,name=actors,proto3" json:"actors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Movie) Reset() {
+	*x = Movie{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Movie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Movie) ProtoMessage() {}
+
+func (x *Movie) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Movie.ProtoReflect.Descriptor instead.
+func (*Movie) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Movie) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Movie) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Movie) GetDescr() string {
+	if x != nil {
+		return x.Descr
+	}
+	return ""
+}
+
+func (x *Movie) GetRelease() string {
+	if x != nil {
+		return x.Release
+	}
+	return ""
+}
+
+func (x *Movie) GetRating() int32 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Movie) GetActors() []int32 {
+	if x != nil {
+		return x.Actors
+	}
+	return nil
+}
+
+type Actor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Sex           string                 `protobuf:"bytes,3,opt,name=sex,proto3" json:"sex,omitempty"`
+	Bd            string                 `protobuf:"bytes,4,opt,name=bd,proto3" json:"bd,omitempty"`
+	Movies        []int32                `protobuf:"varint,5,rep,packed,name=movies,proto3" json:"movies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Actor) Reset() {
+	*x = Actor{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Actor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Actor) ProtoMessage() {}
+
+func (x *Actor) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Actor.ProtoReflect.Descriptor instead.
+func (*Actor) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Actor) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Actor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Actor) GetSex() string {
+	if x != nil {
+		return x.Sex
+	}
+	return ""
+}
+
+func (x *Actor) GetBd() string {
+	if x != nil {
+		return x.Bd
+	}
+	return ""
+}
+
+func (x *Actor) GetMovies() []int32 {
+	if x != nil {
+		return x.Movies
+	}
+	return nil
+}
+
+type CreateMovieRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Movie         *Movie                 `protobuf:"bytes,1,opt,name=movie,proto3" json:"movie,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateMovieRequest) Reset() {
+	*x = CreateMovieRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateMovieRequest) ProtoMessage() {}
+
+func (x *CreateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateMovieRequest.ProtoReflect.Descriptor instead.
+func (*CreateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateMovieRequest) GetMovie() *Movie {
+	if x != nil {
+		return x.Movie
+	}
+	return nil
+}
+
+type UpdateMovieRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Column string                 `protobuf:"bytes,2,opt,name=column,proto3" json:"column,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*UpdateMovieRequest_StringValue
+	//	*UpdateMovieRequest_IntValue
+	Value isUpdateMovieRequest_Value `protobuf_oneof:"value"`
+	// Expected version of the movie, 0 skips the check.
+	Version       int32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateMovieRequest) Reset() {
+	*x = UpdateMovieRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateMovieRequest) ProtoMessage() {}
+
+func (x *UpdateMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateMovieRequest.ProtoReflect.Descriptor instead.
+func (*UpdateMovieRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UpdateMovieRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateMovieRequest) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetValue() isUpdateMovieRequest_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *UpdateMovieRequest) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Value.(*UpdateMovieRequest_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *UpdateMovieRequest) GetIntValue() int32 {
+	if x != nil {
+		if x, ok := x.Value.(*UpdateMovieRequest_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *UpdateMovieRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type isUpdateMovieRequest_Value interface {
+	isUpdateMovieRequest_Value()
+}
+
+type UpdateMovieRequest_StringValue struct {
+	StringValue string `protobuf:"bytes,3,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type UpdateMovieRequest_IntValue struct {
+	IntValue int32 `protobuf:"varint,4,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+func (*UpdateMovieRequest_StringValue) isUpdateMovieRequest_Value() {}
+
+func (*UpdateMovieRequest_IntValue) isUpdateMovieRequest_Value() {}
+
+type DeleteMovieRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Expected version of the movie, 0 skips the check.
+	Version       int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteMovieRequest) Reset() {
+	*x = DeleteMovieRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteMovieRequest) ProtoMessage() {}
+
+func (x *DeleteMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteMovieRequest.ProtoReflect.Descriptor instead.
+func (*DeleteMovieRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteMovieRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteMovieRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RestoreMovieRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreMovieRequest) Reset() {
+	*x = RestoreMovieRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreMovieRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreMovieRequest) ProtoMessage() {}
+
+func (x *RestoreMovieRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreMovieRequest.ProtoReflect.Descriptor instead.
+func (*RestoreMovieRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RestoreMovieRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// CastMember references an actor either by id or by exact name.
+type CastMember struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Actor:
+	//
+	//	*CastMember_ActorId
+	//	*CastMember_ActorName
+	Actor     isCastMember_Actor `protobuf_oneof:"actor"`
+	Character string             `protobuf:"bytes,3,opt,name=character,proto3" json:"character,omitempty"`
+	// Billing order starting from 1, 0 means not billed.
+	Billing       int32 `protobuf:"varint,4,opt,name=billing,proto3" json:"billing,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CastMember) Reset() {
+	*x = CastMember{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CastMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CastMember) ProtoMessage() {}
+
+func (x *CastMember) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CastMember.ProtoReflect.Descriptor instead.
+func (*CastMember) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CastMember) GetActor() isCastMember_Actor {
+	if x != nil {
+		return x.Actor
+	}
+	return nil
+}
+
+func (x *CastMember) GetActorId() int32 {
+	if x != nil {
+		if x, ok := x.Actor.(*CastMember_ActorId); ok {
+			return x.ActorId
+		}
+	}
+	return 0
+}
+
+func (x *CastMember) GetActorName() string {
+	if x != nil {
+		if x, ok := x.Actor.(*CastMember_ActorName); ok {
+			return x.ActorName
+		}
+	}
+	return ""
+}
+
+func (x *CastMember) GetCharacter() string {
+	if x != nil {
+		return x.Character
+	}
+	return ""
+}
+
+func (x *CastMember) GetBilling() int32 {
+	if x != nil {
+		return x.Billing
+	}
+	return 0
+}
+
+type isCastMember_Actor interface {
+	isCastMember_Actor()
+}
+
+type CastMember_ActorId struct {
+	ActorId int32 `protobuf:"varint,1,opt,name=actor_id,json=actorId,proto3,oneof"`
+}
+
+type CastMember_ActorName struct {
+	ActorName string `protobuf:"bytes,2,opt,name=actor_name,json=actorName,proto3,oneof"`
+}
+
+func (*CastMember_ActorId) isCastMember_Actor() {}
+
+func (*CastMember_ActorName) isCastMember_Actor() {}
+
+type MovieActorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MovieId       int32                  `protobuf:"varint,1,opt,name=movie_id,json=movieId,proto3" json:"movie_id,omitempty"`
+	Cast          []*CastMember          `protobuf:"bytes,3,rep,name=cast,proto3" json:"cast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MovieActorsRequest) Reset() {
+	*x = MovieActorsRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MovieActorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MovieActorsRequest) ProtoMessage() {}
+
+func (x *MovieActorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MovieActorsRequest.ProtoReflect.Descriptor instead.
+func (*MovieActorsRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MovieActorsRequest) GetMovieId() int32 {
+	if x != nil {
+		return x.MovieId
+	}
+	return 0
+}
+
+func (x *MovieActorsRequest) GetCast() []*CastMember {
+	if x != nil {
+		return x.Cast
+	}
+	return nil
+}
+
+type ListMoviesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of "rating", "title" or "release". Defaults to "rating".
+	Sort          string `protobuf:"bytes,1,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMoviesRequest) Reset() {
+	*x = ListMoviesRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMoviesRequest) ProtoMessage() {}
+
+func (x *ListMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMoviesRequest.ProtoReflect.Descriptor instead.
+func (*ListMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListMoviesRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type SearchMoviesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchMoviesRequest) Reset() {
+	*x = SearchMoviesRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMoviesRequest) ProtoMessage() {}
+
+func (x *SearchMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMoviesRequest.ProtoReflect.Descriptor instead.
+func (*SearchMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchMoviesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+type ExportMoviesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportMoviesRequest) Reset() {
+	*x = ExportMoviesRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportMoviesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportMoviesRequest) ProtoMessage() {}
+
+func (x *ExportMoviesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportMoviesRequest.ProtoReflect.Descriptor instead.
+func (*ExportMoviesRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{10}
+}
+
+type CreateActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         *Actor                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateActorRequest) Reset() {
+	*x = CreateActorRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateActorRequest) ProtoMessage() {}
+
+func (x *CreateActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateActorRequest.ProtoReflect.Descriptor instead.
+func (*CreateActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateActorRequest) GetActor() *Actor {
+	if x != nil {
+		return x.Actor
+	}
+	return nil
+}
+
+type UpdateActorRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Column string                 `protobuf:"bytes,2,opt,name=column,proto3" json:"column,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*UpdateActorRequest_StringValue
+	//	*UpdateActorRequest_IntValue
+	Value isUpdateActorRequest_Value `protobuf_oneof:"value"`
+	// Expected version of the actor, 0 skips the check.
+	Version       int32 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateActorRequest) Reset() {
+	*x = UpdateActorRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateActorRequest) ProtoMessage() {}
+
+func (x *UpdateActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateActorRequest.ProtoReflect.Descriptor instead.
+func (*UpdateActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateActorRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateActorRequest) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *UpdateActorRequest) GetValue() isUpdateActorRequest_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *UpdateActorRequest) GetStringValue() string {
+	if x != nil {
+		if x, ok := x.Value.(*UpdateActorRequest_StringValue); ok {
+			return x.StringValue
+		}
+	}
+	return ""
+}
+
+func (x *UpdateActorRequest) GetIntValue() int32 {
+	if x != nil {
+		if x, ok := x.Value.(*UpdateActorRequest_IntValue); ok {
+			return x.IntValue
+		}
+	}
+	return 0
+}
+
+func (x *UpdateActorRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type isUpdateActorRequest_Value interface {
+	isUpdateActorRequest_Value()
+}
+
+type UpdateActorRequest_StringValue struct {
+	StringValue string `protobuf:"bytes,3,opt,name=string_value,json=stringValue,proto3,oneof"`
+}
+
+type UpdateActorRequest_IntValue struct {
+	IntValue int32 `protobuf:"varint,4,opt,name=int_value,json=intValue,proto3,oneof"`
+}
+
+func (*UpdateActorRequest_StringValue) isUpdateActorRequest_Value() {}
+
+func (*UpdateActorRequest_IntValue) isUpdateActorRequest_Value() {}
+
+type DeleteActorRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Expected version of the actor, 0 skips the check.
+	Version       int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteActorRequest) Reset() {
+	*x = DeleteActorRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteActorRequest) ProtoMessage() {}
+
+func (x *DeleteActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteActorRequest.ProtoReflect.Descriptor instead.
+func (*DeleteActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteActorRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteActorRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RestoreActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreActorRequest) Reset() {
+	*x = RestoreActorRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreActorRequest) ProtoMessage() {}
+
+func (x *RestoreActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreActorRequest.ProtoReflect.Descriptor instead.
+func (*RestoreActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestoreActorRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListActorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActorsRequest) Reset() {
+	*x = ListActorsRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActorsRequest) ProtoMessage() {}
+
+func (x *ListActorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActorsRequest.ProtoReflect.Descriptor instead.
+func (*ListActorsRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{15}
+}
+
+type ExportActorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportActorsRequest) Reset() {
+	*x = ExportActorsRequest{}
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportActorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportActorsRequest) ProtoMessage() {}
+
+func (x *ExportActorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_filmoteka_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportActorsRequest.ProtoReflect.Descriptor instead.
+func (*ExportActorsRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_filmoteka_proto_rawDescGZIP(), []int{16}
+}
+
+var File_filmoteka_v1_filmoteka_proto protoreflect.FileDescriptor
+
+const file_filmoteka_v1_filmoteka_proto_rawDesc = "" +
+	"\n" +
+	"\x1cfilmoteka/v1/filmoteka.proto\x12\ffilmoteka.v1\x1a\x1bgoogle/protobuf/empty.proto\"\x8d\x01\n" +
+	"\x05Movie\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05descr\x18\x03 \x01(\tR\x05descr\x12\x18\n" +
+	"\arelease\x18\x04 \x01(\tR\arelease\x12\x16\n" +
+	"\x06rating\x18\x05 \x01(\x05R\x06rating\x12\x16\n" +
+	"\x06actors\x18\x06 \x03(\x05R\x06actors\"e\n" +
+	"\x05Actor\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x10\n" +
+	"\x03sex\x18\x03 \x01(\tR\x03sex\x12\x0e\n" +
+	"\x02bd\x18\x04 \x01(\tR\x02bd\x12\x16\n" +
+	"\x06movies\x18\x05 \x03(\x05R\x06movies\"?\n" +
+	"\x12CreateMovieRequest\x12)\n" +
+	"\x05movie\x18\x01 \x01(\v2\x13.filmoteka.v1.MovieR\x05movie\"\xa3\x01\n" +
+	"\x12UpdateMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x16\n" +
+	"\x06column\x18\x02 \x01(\tR\x06column\x12#\n" +
+	"\fstring_value\x18\x03 \x01(\tH\x00R\vstringValue\x12\x1d\n" +
+	"\tint_value\x18\x04 \x01(\x05H\x00R\bintValue\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversionB\a\n" +
+	"\x05value\">\n" +
+	"\x12DeleteMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"%\n" +
+	"\x13RestoreMovieRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\x8b\x01\n" +
+	"\n" +
+	"CastMember\x12\x1b\n" +
+	"\bactor_id\x18\x01 \x01(\x05H\x00R\aactorId\x12\x1f\n" +
+	"\n" +
+	"actor_name\x18\x02 \x01(\tH\x00R\tactorName\x12\x1c\n" +
+	"\tcharacter\x18\x03 \x01(\tR\tcharacter\x12\x18\n" +
+	"\abilling\x18\x04 \x01(\x05R\abillingB\a\n" +
+	"\x05actor\"k\n" +
+	"\x12MovieActorsRequest\x12\x19\n" +
+	"\bmovie_id\x18\x01 \x01(\x05R\amovieId\x12,\n" +
+	"\x04cast\x18\x03 \x03(\v2\x18.filmoteka.v1.CastMemberR\x04castJ\x04\b\x02\x10\x03R\x06actors\"'\n" +
+	"\x11ListMoviesRequest\x12\x12\n" +
+	"\x04sort\x18\x01 \x01(\tR\x04sort\"+\n" +
+	"\x13SearchMoviesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\"\x15\n" +
+	"\x13ExportMoviesRequest\"?\n" +
+	"\x12CreateActorRequest\x12)\n" +
+	"\x05actor\x18\x01 \x01(\v2\x13.filmoteka.v1.ActorR\x05actor\"\xa3\x01\n" +
+	"\x12UpdateActorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x16\n" +
+	"\x06column\x18\x02 \x01(\tR\x06column\x12#\n" +
+	"\fstring_value\x18\x03 \x01(\tH\x00R\vstringValue\x12\x1d\n" +
+	"\tint_value\x18\x04 \x01(\x05H\x00R\bintValue\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x05R\aversionB\a\n" +
+	"\x05value\">\n" +
+	"\x12DeleteActorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\"%\n" +
+	"\x13RestoreActorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\x13\n" +
+	"\x11ListActorsRequest\"\x15\n" +
+	"\x13ExportActorsRequest2\x9c\x05\n" +
+	"\fMovieService\x12D\n" +
+	"\vCreateMovie\x12 .filmoteka.v1.CreateMovieRequest\x1a\x13.filmoteka.v1.Movie\x12G\n" +
+	"\vUpdateMovie\x12 .filmoteka.v1.UpdateMovieRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\vDeleteMovie\x12 .filmoteka.v1.DeleteMovieRequest\x1a\x16.google.protobuf.Empty\x12I\n" +
+	"\fRestoreMovie\x12!.filmoteka.v1.RestoreMovieRequest\x1a\x16.google.protobuf.Empty\x12E\n" +
+	"\tAddActors\x12 .filmoteka.v1.MovieActorsRequest\x1a\x16.google.protobuf.Empty\x12H\n" +
+	"\fDeleteActors\x12 .filmoteka.v1.MovieActorsRequest\x1a\x16.google.protobuf.Empty\x12D\n" +
+	"\n" +
+	"ListMovies\x12\x1f.filmoteka.v1.ListMoviesRequest\x1a\x13.filmoteka.v1.Movie0\x01\x12H\n" +
+	"\fSearchMovies\x12!.filmoteka.v1.SearchMoviesRequest\x1a\x13.filmoteka.v1.Movie0\x01\x12H\n" +
+	"\fExportMovies\x12!.filmoteka.v1.ExportMoviesRequest\x1a\x13.filmoteka.v1.Movie0\x012\xc1\x03\n" +
+	"\fActorService\x12D\n" +
+	"\vCreateActor\x12 .filmoteka.v1.CreateActorRequest\x1a\x13.filmoteka.v1.Actor\x12G\n" +
+	"\vUpdateActor\x12 .filmoteka.v1.UpdateActorRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\vDeleteActor\x12 .filmoteka.v1.DeleteActorRequest\x1a\x16.google.protobuf.Empty\x12I\n" +
+	"\fRestoreActor\x12!.filmoteka.v1.RestoreActorRequest\x1a\x16.google.protobuf.Empty\x12D\n" +
+	"\n" +
+	"ListActors\x12\x1f.filmoteka.v1.ListActorsRequest\x1a\x13.filmoteka.v1.Actor0\x01\x12H\n" +
+	"\fExportActors\x12!.filmoteka.v1.ExportActorsRequest\x1a\x13.filmoteka.v1.Actor0\x01B,Z*filmoteka/pkg/api/filmoteka/v1;filmotekav1b\x06proto3"
+
+var (
+	file_filmoteka_v1_filmoteka_proto_rawDescOnce sync.Once
+	file_filmoteka_v1_filmoteka_proto_rawDescData []byte
+)
+
+func file_filmoteka_v1_filmoteka_proto_rawDescGZIP() []byte {
+	file_filmoteka_v1_filmoteka_proto_rawDescOnce.Do(func() {
+		file_filmoteka_v1_filmoteka_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_filmoteka_v1_filmoteka_proto_rawDesc), len(file_filmoteka_v1_filmoteka_proto_rawDesc)))
+	})
+	return file_filmoteka_v1_filmoteka_proto_rawDescData
+}
+
+var file_filmoteka_v1_filmoteka_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_filmoteka_v1_filmoteka_proto_goTypes = []any{
+	(*Movie)(nil),               // 0: filmoteka.v1.Movie
+	(*Actor)(nil),               // 1: filmoteka.v1.Actor
+	(*CreateMovieRequest)(nil),  // 2: filmoteka.v1.CreateMovieRequest
+	(*UpdateMovieRequest)(nil),  // 3: filmoteka.v1.UpdateMovieRequest
+	(*DeleteMovieRequest)(nil),  // 4: filmoteka.v1.DeleteMovieRequest
+	(*RestoreMovieRequest)(nil), // 5: filmoteka.v1.RestoreMovieRequest
+	(*CastMember)(nil),          // 6: filmoteka.v1.CastMember
+	(*MovieActorsRequest)(nil),  // 7: filmoteka.v1.MovieActorsRequest
+	(*ListMoviesRequest)(nil),   // 8: filmoteka.v1.ListMoviesRequest
+	(*SearchMoviesRequest)(nil), // 9: filmoteka.v1.SearchMoviesRequest
+	(*ExportMoviesRequest)(nil), // 10: filmoteka.v1.ExportMoviesRequest
+	(*CreateActorRequest)(nil),  // 11: filmoteka.v1.CreateActorRequest
+	(*UpdateActorRequest)(nil),  // 12: filmoteka.v1.UpdateActorRequest
+	(*DeleteActorRequest)(nil),  // 13: filmoteka.v1.DeleteActorRequest
+	(*RestoreActorRequest)(nil), // 14: filmoteka.v1.RestoreActorRequest
+	(*ListActorsRequest)(nil),   // 15: filmoteka.v1.ListActorsRequest
+	(*ExportActorsRequest)(nil), // 16: filmoteka.v1.ExportActorsRequest
+	(*emptypb.Empty)(nil),       // 17: google.protobuf.Empty
+}
+var file_filmoteka_v1_filmoteka_proto_depIdxs = []int32{
+	0,  // 0: filmoteka.v1.CreateMovieRequest.movie:type_name -> filmoteka.v1.Movie
+	6,  // 1: filmoteka.v1.MovieActorsRequest.cast:type_name -> filmoteka.v1.CastMember
+	1,  // 2: filmoteka.v1.CreateActorRequest.actor:type_name -> filmoteka.v1.Actor
+	2,  // 3: filmoteka.v1.MovieService.CreateMovie:input_type -> filmoteka.v1.CreateMovieRequest
+	3,  // 4: filmoteka.v1.MovieService.UpdateMovie:input_type -> filmoteka.v1.UpdateMovieRequest
+	4,  // 5: filmoteka.v1.MovieService.DeleteMovie:input_type -> filmoteka.v1.DeleteMovieRequest
+	5,  // 6: filmoteka.v1.MovieService.RestoreMovie:input_type -> filmoteka.v1.RestoreMovieRequest
+	7,  // 7: filmoteka.v1.MovieService.AddActors:input_type -> filmoteka.v1.MovieActorsRequest
+	7,  // 8: filmoteka.v1.MovieService.DeleteActors:input_type -> filmoteka.v1.MovieActorsRequest
+	8,  // 9: filmoteka.v1.MovieService.ListMovies:input_type -> filmoteka.v1.ListMoviesRequest
+	9,  // 10: filmoteka.v1.MovieService.SearchMovies:input_type -> filmoteka.v1.SearchMoviesRequest
+	10, // 11: filmoteka.v1.MovieService.ExportMovies:input_type -> filmoteka.v1.ExportMoviesRequest
+	11, // 12: filmoteka.v1.ActorService.CreateActor:input_type -> filmoteka.v1.CreateActorRequest
+	12, // 13: filmoteka.v1.ActorService.UpdateActor:input_type -> filmoteka.v1.UpdateActorRequest
+	13, // 14: filmoteka.v1.ActorService.DeleteActor:input_type -> filmoteka.v1.DeleteActorRequest
+	14, // 15: filmoteka.v1.ActorService.RestoreActor:input_type -> filmoteka.v1.RestoreActorRequest
+	15, // 16: filmoteka.v1.ActorService.ListActors:input_type -> filmoteka.v1.ListActorsRequest
+	16, // 17: filmoteka.v1.ActorService.ExportActors:input_type -> filmoteka.v1.ExportActorsRequest
+	0,  // 18: filmoteka.v1.MovieService.CreateMovie:output_type -> filmoteka.v1.Movie
+	17, // 19: filmoteka.v1.MovieService.UpdateMovie:output_type -> google.protobuf.Empty
+	17, // 20: filmoteka.v1.MovieService.DeleteMovie:output_type -> google.protobuf.Empty
+	17, // 21: filmoteka.v1.MovieService.RestoreMovie:output_type -> google.protobuf.Empty
+	17, // 22: filmoteka.v1.MovieService.AddActors:output_type -> google.protobuf.Empty
+	17, // 23: filmoteka.v1.MovieService.DeleteActors:output_type -> google.protobuf.Empty
+	0,  // 24: filmoteka.v1.MovieService.ListMovies:output_type -> filmoteka.v1.Movie
+	0,  // 25: filmoteka.v1.MovieService.SearchMovies:output_type -> filmoteka.v1.Movie
+	0,  // 26: filmoteka.v1.MovieService.ExportMovies:output_type -> filmoteka.v1.Movie
+	1,  // 27: filmoteka.v1.ActorService.CreateActor:output_type -> filmoteka.v1.Actor
+	17, // 28: filmoteka.v1.ActorService.UpdateActor:output_type -> google.protobuf.Empty
+	17, // 29: filmoteka.v1.ActorService.DeleteActor:output_type -> google.protobuf.Empty
+	17, // 30: filmoteka.v1.ActorService.RestoreActor:output_type -> google.protobuf.Empty
+	1,  // 31: filmoteka.v1.ActorService.ListActors:output_type -> filmoteka.v1.Actor
+	1,  // 32: filmoteka.v1.ActorService.ExportActors:output_type -> filmoteka.v1.Actor
+	18, // [18:33] is the sub-list for method output_type
+	3,  // [3:18] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_filmoteka_v1_filmoteka_proto_init() }
+func file_filmoteka_v1_filmoteka_proto_init() {
+	if File_filmoteka_v1_filmoteka_proto != nil {
+		return
+	}
+	file_filmoteka_v1_filmoteka_proto_msgTypes[3].OneofWrappers = []any{
+		(*UpdateMovieRequest_StringValue)(nil),
+		(*UpdateMovieRequest_IntValue)(nil),
+	}
+	file_filmoteka_v1_filmoteka_proto_msgTypes[6].OneofWrappers = []any{
+		(*CastMember_ActorId)(nil),
+		(*CastMember_ActorName)(nil),
+	}
+	file_filmoteka_v1_filmoteka_proto_msgTypes[12].OneofWrappers = []any{
+		(*UpdateActorRequest_StringValue)(nil),
+		(*UpdateActorRequest_IntValue)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_filmoteka_v1_filmoteka_proto_rawDesc), len(file_filmoteka_v1_filmoteka_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_filmoteka_v1_filmoteka_proto_goTypes,
+		DependencyIndexes: file_filmoteka_v1_filmoteka_proto_depIdxs,
+		MessageInfos:      file_filmoteka_v1_filmoteka_proto_msgTypes,
+	}.Build()
+	File_filmoteka_v1_filmoteka_proto = out.File
+	file_filmoteka_v1_filmoteka_proto_goTypes = nil
+	file_filmoteka_v1_filmoteka_proto_depIdxs = nil
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type Actor struct {
+	Id     int    `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Sex    rune   `json:"sex"`
+	Bd     string `json:"bd"`
+	Movies []int  `json:"movies,omitempty"`
+	// Version is the actor version to pass to UpdateActor and DeleteActor.
+	Version int `json:"-"`
+}
+
+func (c *Client) CreateActor(ctx context.Context, actor *Actor) (*Actor, error) {
+	created := &Actor{}
+	if err := c.do(ctx, http.MethodPost, "/actors", actor, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) GetActor(ctx context.Context, id int) (*Actor, error) {
+	actor := &Actor{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/actors/%d", id), nil, actor, readVersion(&actor.Version)); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+func (c *Client) ListActors(ctx context.Context) ([]*Actor, error) {
+	var actors []*Actor
+	if err := c.do(ctx, http.MethodGet, "/actors", nil, &actors); err != nil {
+		return nil, err
+	}
+	return actors, nil
+}
+
+// UpdateActor sets a single column. Unless version is 0 the update fails
+// with 412 when the actor has changed since that version.
+func (c *Client) UpdateActor(ctx context.Context, id int, version int, column string, value interface{}) error {
+	body := map[string]interface{}{"column": column, "value": value}
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/actors/%d", id), body, nil, ifMatch(version))
+}
+
+// DeleteActor removes an actor, checking version like UpdateActor.
+func (c *Client) DeleteActor(ctx context.Context, id int, version int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/actors/%d", id), nil, nil, ifMatch(version))
+}
+
+// RestoreActor brings back a deleted actor, it can not be restored once purged.
+func (c *Client) RestoreActor(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/actors/%d/restore", id), nil, nil)
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Scopes an API key can be granted. ScopeAdmin grants every other scope.
+const (
+	ScopeMoviesRead  = "movies:read"
+	ScopeMoviesWrite = "movies:write"
+	ScopeActorsRead  = "actors:read"
+	ScopeActorsWrite = "actors:write"
+	ScopeAdmin       = "admin"
+)
+
+// APIKey is a credential of a service. Key is set only in the response to
+// IssueAPIKey.
+type APIKey struct {
+	Id         int        `json:"id,omitempty"`
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	Prefix     string     `json:"prefix,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// IssueAPIKey generates a key with the name, scopes and expiry of key. The
+// returned key is the only copy of it.
+func (c *Client) IssueAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	issued := &APIKey{}
+	if err := c.do(ctx, http.MethodPost, "/admin/apikeys", key, issued); err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// ListAPIKeys returns every key that was not revoked, without the keys
+// themselves.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]*APIKey, error) {
+	var keys []*APIKey
+	if err := c.do(ctx, http.MethodGet, "/admin/apikeys", nil, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *Client) RevokeAPIKey(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/admin/apikeys/"+strconv.Itoa(id), nil, nil)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// AuditEntry records a single change of a movie or an actor.
+type AuditEntry struct {
+	Id        int64           `json:"id"`
+	User      string          `json:"user"`
+	At        time.Time       `json:"at"`
+	Entity    string          `json:"entity"`
+	EntityId  int             `json:"entity_id"`
+	Operation string          `json:"operation"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+}
+
+// AuditPage is a page of the audit log, pass Next as after to get the next
+// one. Next is 0 on the last page.
+type AuditPage struct {
+	Entries []*AuditEntry `json:"entries"`
+	Next    int64         `json:"next,omitempty"`
+}
+
+// ListAudit pages through the audit log. An empty entity and zero id match
+// every entry, a zero limit uses the server default.
+func (c *Client) ListAudit(ctx context.Context, entity string, id int, after int64, limit int) (*AuditPage, error) {
+	query := url.Values{}
+	if entity != "" {
+		query.Set("entity", entity)
+	}
+	if id != 0 {
+		query.Set("id", strconv.Itoa(id))
+	}
+	if after != 0 {
+		query.Set("after", strconv.FormatInt(after, 10))
+	}
+	if limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	page := &AuditPage{}
+	if err := c.do(ctx, http.MethodGet, "/audit?"+query.Encode(), nil, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operations a batch can contain.
+const (
+	OpCreateMovie = "create_movie"
+	OpUpdateMovie = "update_movie"
+	OpDeleteMovie = "delete_movie"
+	OpCreateActor = "create_actor"
+	OpUpdateActor = "update_actor"
+	OpDeleteActor = "delete_actor"
+	OpLink        = "link"
+	OpUnlink      = "unlink"
+)
+
+// BatchOperation is a single step of a batch. Id and ActorId take either an
+// int id or the string Ref of a create operation earlier in the batch.
+type BatchOperation struct {
+	Op      string            `json:"op"`
+	Ref     string            `json:"ref,omitempty"`
+	Id      interface{}       `json:"id,omitempty"`
+	Version int               `json:"version,omitempty"`
+	Movie   *Movie            `json:"movie,omitempty"`
+	Actor   *Actor            `json:"actor,omitempty"`
+	Column  string            `json:"column,omitempty"`
+	Value   interface{}       `json:"value,omitempty"`
+	Actors  []BatchCastMember `json:"actors,omitempty"`
+}
+
+type BatchCastMember struct {
+	ActorId   interface{} `json:"actor_id,omitempty"`
+	ActorName string      `json:"actor_name,omitempty"`
+	Character string      `json:"character,omitempty"`
+	Billing   int         `json:"billing,omitempty"`
+}
+
+// BatchResult reports the row an operation applied to.
+type BatchResult struct {
+	Op  string `json:"op"`
+	Ref string `json:"ref,omitempty"`
+	Id  int    `json:"id"`
+}
+
+// Batch applies operations in one transaction. On failure nothing is
+// applied and APIError.Operation holds the index of the failed operation.
+func (c *Client) Batch(ctx context.Context, operations []BatchOperation) ([]BatchResult, error) {
+	response := struct {
+		Results []BatchResult `json:"results"`
+	}{}
+	request := struct {
+		Operations []BatchOperation `json:"operations"`
+	}{Operations: operations}
+
+	if err := c.do(ctx, http.MethodPost, "/batch", request, &response); err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
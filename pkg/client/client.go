@@ -0,0 +1,242 @@
+// Package client is a typed Go client for the filmoteka HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetries = 3
+	defaultBackoff = 100 * time.Millisecond
+)
+
+type Client struct {
+	baseURL    string
+	token      string
+	apiKey     string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+type Option func(*Client)
+
+// WithToken sends the token in the Authorization header of every request.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithAPIKey authenticates every request with an API key issued by the
+// server. It takes the place of a token.
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithRetry sets how many times idempotent calls are retried and the initial
+// backoff, which doubles after every attempt. POST calls count as idempotent
+// since they carry an Idempotency-Key. DeleteActors does not, it is only
+// retried when rate limited.
+func WithRetry(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned for every non-2xx response. Type holds the
+// core.MyError type reported by the server, e.g. "ErrMovieDoesNotExist".
+type APIError struct {
+	StatusCode int
+	Type       string   `json:"type"`
+	Message    string   `json:"message"`
+	Ids        []int    `json:"ids,omitempty"`
+	Names      []string `json:"names,omitempty"`
+	// Operation is the index of the failed operation of a batch.
+	Operation *int `json:"operation,omitempty"`
+}
+
+func (err *APIError) Error() string {
+	return fmt.Sprintf("filmoteka: %d %s: %s", err.StatusCode, err.Type, err.Message)
+}
+
+// call carries per-request headers and what to read back from the response.
+type call struct {
+	ifMatch        int
+	idempotencyKey string
+	version        *int
+	// unsafe requests may have been applied when they fail with a 5xx or
+	// a network error, so they are retried only when rate limited.
+	unsafe bool
+}
+
+type callOption func(*call)
+
+// ifMatch makes the request fail with 412 unless the resource is at version.
+// Version 0 sends no precondition.
+func ifMatch(version int) callOption {
+	return func(c *call) { c.ifMatch = version }
+}
+
+// notIdempotent marks a request whose retry could fail because the first
+// attempt went through, e.g. with 404 once the actors it removes are gone.
+func notIdempotent() callOption {
+	return func(c *call) { c.unsafe = true }
+}
+
+// readVersion stores the version carried by the ETag of the response.
+func readVersion(version *int) callOption {
+	return func(c *call) { c.version = version }
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, opts ...callOption) error {
+
+	options := &call{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	if method == http.MethodPost {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return err
+		}
+		options.idempotencyKey = key
+	}
+
+	attempts := 1
+	if method == http.MethodGet || method == http.MethodDelete || options.idempotencyKey != "" {
+		attempts += c.retries
+	}
+
+	backoff := c.backoff
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			wait := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = c.attempt(ctx, method, path, payload, out, options)
+		if !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// attempt sends one request and reports whether it is worth retrying.
+func (c *Client) attempt(ctx context.Context, method, path string, payload []byte, out interface{}, options *call) (bool, error) {
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return false, err
+	}
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.apiKey)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if options.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", options.idempotencyKey)
+	}
+	if options.ifMatch != 0 {
+		req.Header.Set("If-Match", fmt.Sprintf(`"%d"`, options.ifMatch))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil && !options.unsafe, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		// A conflict on the idempotency key means an earlier attempt is still
+		// running, its response is replayed once it is done.
+		retry := (resp.StatusCode >= 500 && !options.unsafe) || resp.StatusCode == http.StatusTooManyRequests ||
+			apiErr.Type == "ErrIdempotencyKeyInUse"
+		return retry, apiErr
+	}
+
+	if options.version != nil {
+		*options.version = etagVersion(resp.Header.Get("ETag"))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	return false, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newIdempotencyKey returns a random key shared by all attempts of a call.
+func newIdempotencyKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := crand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// etagVersion extracts the row version from an ETag such as "3" or "3.9f2c".
+func etagVersion(etag string) int {
+	number, _, _ := strings.Cut(strings.Trim(etag, `"`), ".")
+	version, _ := strconv.Atoi(number)
+	return version
+}
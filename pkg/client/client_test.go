@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		status   int
+		call     func(c *Client) error
+		attempts int32
+	}{
+		{"get after a 5xx", http.StatusBadGateway, func(c *Client) error {
+			_, err := c.ListActors(context.Background())
+			return err
+		}, 3},
+		{"delete actors after a 5xx", http.StatusBadGateway, func(c *Client) error {
+			return c.DeleteActors(context.Background(), 1, []CastMember{{ActorId: 2}})
+		}, 1},
+		{"delete actors when rate limited", http.StatusTooManyRequests, func(c *Client) error {
+			return c.DeleteActors(context.Background(), 1, []CastMember{{ActorId: 2}})
+		}, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("X-User") != "" {
+					t.Error("X-User header sent")
+				}
+				attempts.Add(1)
+				w.WriteHeader(test.status)
+			}))
+			defer server.Close()
+
+			err := test.call(New(server.URL, WithRetry(2, time.Millisecond)))
+			if err == nil {
+				t.Fatal("call succeeded")
+			}
+			if got := attempts.Load(); got != test.attempts {
+				t.Errorf("%d attempts, want %d", got, test.attempts)
+			}
+		})
+	}
+}
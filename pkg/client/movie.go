@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Movie struct {
+	Id      int    `json:"id,omitempty"`
+	Title   string `json:"title"`
+	Descr   string `json:"descr"`
+	Release string `json:"release"`
+	Rating  int    `json:"rating"`
+	Actors  []int  `json:"actors"`
+}
+
+// MovieDetail is a movie with its cast expanded into actor objects.
+type MovieDetail struct {
+	Id      int          `json:"id"`
+	Title   string       `json:"title"`
+	Descr   string       `json:"descr"`
+	Release string       `json:"release"`
+	Rating  int          `json:"rating"`
+	Actors  []*CastActor `json:"actors"`
+	// Version is the movie version to pass to UpdateMovie and DeleteMovie.
+	Version int `json:"-"`
+}
+
+// CastActor is an actor as credited in a movie.
+type CastActor struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Sex       rune   `json:"sex"`
+	Bd        string `json:"bd"`
+	Character string `json:"character,omitempty"`
+	Billing   int    `json:"billing,omitempty"`
+}
+
+// CastMember references an actor either by id or by exact name. Character
+// and Billing are optional.
+type CastMember struct {
+	ActorId   int    `json:"actor_id,omitempty"`
+	ActorName string `json:"actor_name,omitempty"`
+	Character string `json:"character,omitempty"`
+	Billing   int    `json:"billing,omitempty"`
+}
+
+const (
+	SortByRating  = "rating"
+	SortByTitle   = "title"
+	SortByRelease = "release"
+)
+
+func (c *Client) CreateMovie(ctx context.Context, movie *Movie) (*Movie, error) {
+	created := &Movie{}
+	if err := c.do(ctx, http.MethodPost, "/movies", movie, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetMovie returns a movie with its cast.
+func (c *Client) GetMovie(ctx context.Context, id int) (*MovieDetail, error) {
+	movie := &MovieDetail{}
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/movies/%d?include=actors", id), nil, movie, readVersion(&movie.Version)); err != nil {
+		return nil, err
+	}
+	return movie, nil
+}
+
+// ListMovies returns all movies ordered by one of the Sort* constants.
+func (c *Client) ListMovies(ctx context.Context, sort string) ([]*Movie, error) {
+	var movies []*Movie
+	path := "/movies?sort=" + url.QueryEscape(sort)
+	if err := c.do(ctx, http.MethodGet, path, nil, &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+// SearchMovies finds movies by a fragment of the title or of an actor name.
+func (c *Client) SearchMovies(ctx context.Context, query string) ([]*Movie, error) {
+	var movies []*Movie
+	path := "/movies/search?q=" + url.QueryEscape(query)
+	if err := c.do(ctx, http.MethodGet, path, nil, &movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+// UpdateMovie sets a single column. Unless version is 0 the update fails
+// with 412 when the movie has changed since that version.
+func (c *Client) UpdateMovie(ctx context.Context, id int, version int, column string, value interface{}) error {
+	body := map[string]interface{}{"column": column, "value": value}
+	return c.do(ctx, http.MethodPatch, fmt.Sprintf("/movies/%d", id), body, nil, ifMatch(version))
+}
+
+// DeleteMovie removes a movie, checking version like UpdateMovie.
+func (c *Client) DeleteMovie(ctx context.Context, id int, version int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/movies/%d", id), nil, nil, ifMatch(version))
+}
+
+// RestoreMovie brings back a deleted movie, it can not be restored once purged.
+func (c *Client) RestoreMovie(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/movies/%d/restore", id), nil, nil)
+}
+
+func (c *Client) AddActors(ctx context.Context, movieID int, cast []CastMember) error {
+	body := map[string]interface{}{"actors": cast}
+	return c.do(ctx, http.MethodPost, fmt.Sprintf("/movies/%d/actors", movieID), body, nil)
+}
+
+// DeleteActors unlinks cast from a movie. It is not retried after a 5xx,
+// the retry would answer 404 when the first attempt went through.
+func (c *Client) DeleteActors(ctx context.Context, movieID int, cast []CastMember) error {
+	body := map[string]interface{}{"actors": cast}
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/movies/%d/actors", movieID), body, nil, notIdempotent())
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VerifyWebhook checks the signature of a webhook delivery received with
+// the given headers and body, and rejects deliveries signed more than
+// tolerance ago to limit replays.
+func VerifyWebhook(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp := header.Get("X-Filmoteka-Timestamp")
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("filmoteka: invalid webhook timestamp %q", timestamp)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("filmoteka: webhook timestamp is outside the tolerance")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Filmoteka-Signature"))) {
+		return fmt.Errorf("filmoteka: invalid webhook signature")
+	}
+	return nil
+}